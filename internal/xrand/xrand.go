@@ -0,0 +1,81 @@
+// Package xrand provides the randomness shared by the random helpers of xiter and xslice,
+// it uses the global source of math/rand unless a Rand is set.
+package xrand
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Rand is a source of uniformly distributed random uint64 values.
+type Rand interface {
+	Uint64() uint64
+}
+
+// Options holds the Rand used by random helpers, nil Rand means the global source of math/rand.
+type Options struct {
+	Rand Rand
+}
+
+// Float64 returns a random number in [0.0, 1.0).
+func (o *Options) Float64() float64 {
+	if o.Rand == nil {
+		return rand.Float64() //nolint:gosec
+	}
+	return float64(o.Rand.Uint64()>>11) / (1 << 53)
+}
+
+// Int63n returns a random number in [0, n), it panics if n <= 0.
+func (o *Options) Int63n(n int64) int64 {
+	if o.Rand == nil {
+		return rand.Int63n(n) //nolint:gosec
+	}
+	if n <= 0 {
+		panic("invalid argument to Int63n")
+	}
+	// reject the values above the largest multiple of n to avoid modulo bias.
+	un := uint64(n)
+	limit := math.MaxUint64 - math.MaxUint64%un
+	for {
+		if v := o.Rand.Uint64(); v < limit {
+			return int64(v % un)
+		}
+	}
+}
+
+// Intn returns a random number in [0, n), it panics if n <= 0.
+func (o *Options) Intn(n int) int {
+	if o.Rand == nil {
+		return rand.Intn(n) //nolint:gosec
+	}
+	if n <= 0 {
+		panic("invalid argument to Intn")
+	}
+	return int(o.Int63n(int64(n)))
+}
+
+// Shuffle pseudo-randomizes the order of n elements with swap like rand.Shuffle.
+func (o *Options) Shuffle(n int, swap func(i, j int)) {
+	if o.Rand == nil {
+		rand.Shuffle(n, swap)
+		return
+	}
+	for i := n - 1; i > 0; i-- {
+		swap(i, o.Intn(i+1))
+	}
+}
+
+// Perm returns a random permutation of [0, n).
+func (o *Options) Perm(n int) []int {
+	if o.Rand == nil {
+		return rand.Perm(n)
+	}
+	p := make([]int, n)
+	for i := range p {
+		p[i] = i
+	}
+	o.Shuffle(n, func(i, j int) {
+		p[i], p[j] = p[j], p[i]
+	})
+	return p
+}
@@ -0,0 +1,13 @@
+//go:build go1.22
+// +build go1.22
+
+package xrand
+
+import randv2 "math/rand/v2"
+
+// the generators of math/rand/v2 can be used as Rand directly.
+var (
+	_ Rand = (*randv2.Rand)(nil)
+	_ Rand = (*randv2.PCG)(nil)
+	_ Rand = (*randv2.ChaCha8)(nil)
+)
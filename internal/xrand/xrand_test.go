@@ -0,0 +1,48 @@
+package xrand_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/dashjay/xiter/internal/xrand"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRand(t *testing.T) {
+	seeded := func() *xrand.Options {
+		return &xrand.Options{Rand: rand.New(rand.NewSource(1))}
+	}
+	for _, o := range []*xrand.Options{{}, seeded()} {
+		for i := 0; i < 1000; i++ {
+			f := o.Float64()
+			assert.True(t, f >= 0 && f < 1)
+			assert.True(t, o.Intn(7) < 7 && o.Intn(7) >= 0)
+			assert.True(t, o.Int63n(1<<62) < 1<<62)
+		}
+		assert.Equal(t, 0, o.Intn(1))
+
+		p := o.Perm(100)
+		sort.Ints(p)
+		for i := range p {
+			assert.Equal(t, i, p[i])
+		}
+		assert.Panics(t, func() { o.Intn(0) })
+		assert.Panics(t, func() { o.Int63n(-1) })
+	}
+
+	a, b := seeded(), seeded()
+	assert.Equal(t, a.Perm(100), b.Perm(100))
+	assert.Equal(t, a.Float64(), b.Float64())
+	assert.Equal(t, a.Intn(1000), b.Intn(1000))
+
+	// uniform
+	o := seeded()
+	counts := make([]int, 3)
+	for i := 0; i < 30000; i++ {
+		counts[o.Intn(3)]++
+	}
+	for _, c := range counts {
+		assert.InDelta(t, 10000, c, 300)
+	}
+}
@@ -0,0 +1,350 @@
+package xio
+
+import (
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+
+	"github.com/dashjay/xiter/xiter"
+)
+
+// CSVOption configures the CSV readers and writers.
+type CSVOption func(*csvOptions)
+
+type csvOptions struct {
+	comma      rune
+	comment    rune
+	lazyQuotes bool
+	useCRLF    bool
+}
+
+// WithComma sets the field delimiter, default is ','.
+func WithComma(r rune) CSVOption {
+	return func(o *csvOptions) {
+		o.comma = r
+	}
+}
+
+// WithComment makes the readers ignore the lines beginning with r, default is 0, which disables comments.
+func WithComment(r rune) CSVOption {
+	return func(o *csvOptions) {
+		o.comment = r
+	}
+}
+
+// WithLazyQuotes makes the readers accept quotes in an unquoted field and non-doubled quotes in a quoted field.
+func WithLazyQuotes() CSVOption {
+	return func(o *csvOptions) {
+		o.lazyQuotes = true
+	}
+}
+
+// WithCRLF makes the writers end lines with \r\n instead of \n.
+func WithCRLF() CSVOption {
+	return func(o *csvOptions) {
+		o.useCRLF = true
+	}
+}
+
+func newCSVOptions(opts []CSVOption) *csvOptions {
+	o := &csvOptions{comma: ','}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func (o *csvOptions) newReader(r io.Reader) *csv.Reader {
+	cr := csv.NewReader(r)
+	cr.Comma = o.comma
+	cr.Comment = o.comment
+	cr.LazyQuotes = o.lazyQuotes
+	// records may have different numbers of fields, the struct decoder handles missing columns itself.
+	cr.FieldsPerRecord = -1
+	return cr
+}
+
+func (o *csvOptions) newWriter(w io.Writer) *csv.Writer {
+	cw := csv.NewWriter(w)
+	cw.Comma = o.comma
+	cw.UseCRLF = o.useCRLF
+	return cw
+}
+
+// ReadCSV returns a SeqErr over the records of r in the CSV format, every record is a new slice of fields.
+// Records may have different numbers of fields.
+//
+// EXAMPLE:
+//
+//	records, err := xiter.TryToSlice(xio.ReadCSV(strings.NewReader("a,b\n1,2\n")))
+//	records 👉 [[a b] [1 2]]
+func ReadCSV(r io.Reader, opts ...CSVOption) xiter.SeqErr[[]string] {
+	o := newCSVOptions(opts)
+	return func(yield func([]string, error) bool) {
+		cr := o.newReader(r)
+		for {
+			record, line, err := readCSVRecord(cr)
+			if err != nil {
+				if !errors.Is(err, io.EOF) && yield(nil, err) && line > 0 {
+					continue
+				}
+				return
+			}
+			if !yield(record, nil) {
+				return
+			}
+		}
+	}
+}
+
+// readCSVRecord reads the next record and its line number,
+// line is 0 if the error is not about a record, and the reading can not go on then.
+func readCSVRecord(cr *csv.Reader) (record []string, line int, err error) {
+	record, err = cr.Read()
+	if err != nil {
+		var pe *csv.ParseError
+		if errors.As(err, &pe) {
+			return nil, pe.StartLine, &RecordError{Line: pe.StartLine, Err: pe.Err}
+		}
+		return nil, 0, err
+	}
+	line, _ = cr.FieldPos(0)
+	return record, line, nil
+}
+
+// DecodeCSV returns a SeqErr over the records of r decoded into T, which must be a struct.
+// The first record is the header, and the columns are mapped to the exported fields of T by their `csv:"name"` tags,
+// or by their names if they have no tag. A field with the tag `csv:"-"` is ignored,
+// and so are the columns without a field and the fields without a column.
+//
+// A field can be a string, bool, integer, float, or implement encoding.TextUnmarshaler,
+// an empty value leaves the field zero. A value which can not be parsed is reported by a *RecordError with the column.
+//
+// EXAMPLE:
+//
+//	type User struct {
+//		Name string `csv:"name"`
+//		Age  int    `csv:"age"`
+//	}
+//	users, err := xiter.TryToSlice(xio.DecodeCSV[User](strings.NewReader("name,age\nalice,30\nbob,25\n")))
+//	users 👉 [{alice 30} {bob 25}]
+func DecodeCSV[T any](r io.Reader, opts ...CSVOption) xiter.SeqErr[T] {
+	o := newCSVOptions(opts)
+	return func(yield func(T, error) bool) {
+		var zero T
+		fields, err := csvFieldsOf(reflect.TypeOf(zero))
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		cr := o.newReader(r)
+		header, _, err := readCSVRecord(cr)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				yield(zero, err)
+			}
+			return
+		}
+		// columns[i] is the field of the i-th column, nil if there is no field for it.
+		columns := make([]*csvField, len(header))
+		for i, name := range header {
+			for j := range fields {
+				if fields[j].name == name {
+					columns[i] = &fields[j]
+					break
+				}
+			}
+		}
+		for {
+			record, line, err := readCSVRecord(cr)
+			if err != nil {
+				if !errors.Is(err, io.EOF) && yield(zero, err) && line > 0 {
+					continue
+				}
+				return
+			}
+			var v T
+			if err = decodeCSVRecord(reflect.ValueOf(&v).Elem(), columns, record, line); err != nil {
+				v = zero
+			}
+			if !yield(v, err) {
+				return
+			}
+		}
+	}
+}
+
+func decodeCSVRecord(v reflect.Value, columns []*csvField, record []string, line int) error {
+	for i, s := range record {
+		if i >= len(columns) || columns[i] == nil || s == "" {
+			continue
+		}
+		if err := parseCSVValue(v.FieldByIndex(columns[i].index), s); err != nil {
+			return &RecordError{Line: line, Column: columns[i].name, Err: err}
+		}
+	}
+	return nil
+}
+
+// WriteCSV writes the records of seq to w in the CSV format.
+// It stops at the first error and returns it.
+//
+// EXAMPLE:
+//
+//	var buf bytes.Buffer
+//	_ = xio.WriteCSV(&buf, xiter.FromSlice([][]string{{"a", "b"}, {"1", "2"}}))
+//	buf.String() 👉 "a,b\n1,2\n"
+func WriteCSV(w io.Writer, seq xiter.Seq[[]string], opts ...CSVOption) (err error) {
+	cw := newCSVOptions(opts).newWriter(w)
+	seq(func(record []string) bool {
+		if err = cw.Write(record); err != nil {
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// EncodeCSV writes the values of seq to w in the CSV format, with a header built from the fields of T,
+// which must be a struct. The fields are mapped like DecodeCSV, and formatted with encoding.TextMarshaler if they
+// implement it.
+//
+// EXAMPLE:
+//
+//	var buf bytes.Buffer
+//	_ = xio.EncodeCSV(&buf, xiter.FromSlice([]User{{Name: "alice", Age: 30}}))
+//	buf.String() 👉 "name,age\nalice,30\n"
+func EncodeCSV[T any](w io.Writer, seq xiter.Seq[T], opts ...CSVOption) (err error) {
+	var zero T
+	fields, err := csvFieldsOf(reflect.TypeOf(zero))
+	if err != nil {
+		return err
+	}
+	header := make([]string, 0, len(fields))
+	for _, f := range fields {
+		header = append(header, f.name)
+	}
+	cw := newCSVOptions(opts).newWriter(w)
+	if err = cw.Write(header); err != nil {
+		return err
+	}
+	line := 1
+	record := make([]string, len(fields))
+	seq(func(v T) bool {
+		line++
+		rv := reflect.ValueOf(&v).Elem()
+		for i, f := range fields {
+			s, e := formatCSVValue(rv.FieldByIndex(f.index))
+			if e != nil {
+				err = &RecordError{Line: line, Column: f.name, Err: e}
+				return false
+			}
+			record[i] = s
+		}
+		if err = cw.Write(record); err != nil {
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type csvField struct {
+	name  string
+	index []int
+}
+
+func csvFieldsOf(t reflect.Type) ([]csvField, error) {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("xio: %v is not a struct", t)
+	}
+	fields := make([]csvField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("csv"); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		fields = append(fields, csvField{name: name, index: f.Index})
+	}
+	return fields, nil
+}
+
+func parseCSVValue(v reflect.Value, s string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %v", v.Type())
+	}
+	return nil
+}
+
+func formatCSVValue(v reflect.Value) (string, error) {
+	if m, ok := v.Addr().Interface().(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		return string(text), err
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	default:
+		return "", fmt.Errorf("unsupported type %v", v.Type())
+	}
+}
@@ -0,0 +1,159 @@
+package xio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dashjay/xiter/xiter"
+)
+
+// DecodeJSONArray returns a SeqErr over the elements of the JSON array in r, every element is decoded into a new T
+// when it is reached, so the whole array is never loaded into memory. A null is treated as an empty array.
+//
+// An element which can not be decoded into T is reported by a *RecordError with its index,
+// and the decoding goes on if the consumer keeps iterating. A syntax error or an error of r is yielded,
+// and the iteration stops then.
+//
+// EXAMPLE:
+//
+//	values, err := xiter.TryToSlice(xio.DecodeJSONArray[int](strings.NewReader("[1, 2, 3]")))
+//	values 👉 [1 2 3]
+func DecodeJSONArray[T any](r io.Reader) xiter.SeqErr[T] {
+	return DecodeJSONArrayAt[T](r, "")
+}
+
+// DecodeJSONArrayAt is like DecodeJSONArray, but decodes the array selected by path in the JSON document of r.
+// path is a dot-separated list of object keys or array indexes, such as "data.items" or "pages.0.items",
+// an empty path selects the document itself. The values before the array are skipped token by token,
+// and the values after it are not read at all.
+//
+// EXAMPLE:
+//
+//	r := strings.NewReader(`{"total": 2, "data": {"items": [{"id": 1}, {"id": 2}]}}`)
+//	items, err := xiter.TryToSlice(xio.DecodeJSONArrayAt[Item](r, "data.items"))
+//	items 👉 [{1} {2}]
+func DecodeJSONArrayAt[T any](r io.Reader, path string) xiter.SeqErr[T] {
+	var segments []string
+	if path != "" {
+		segments = strings.Split(path, ".")
+	}
+	return func(yield func(T, error) bool) {
+		var zero T
+		dec := json.NewDecoder(r)
+		if err := seekJSONPath(dec, segments); err != nil {
+			yield(zero, noEOF(err))
+			return
+		}
+		tok, err := dec.Token()
+		if err != nil {
+			yield(zero, noEOF(err))
+			return
+		}
+		if tok == nil {
+			return
+		}
+		if tok != json.Delim('[') {
+			yield(zero, fmt.Errorf("xio: value at %q is %v, not an array", path, tok))
+			return
+		}
+		for i := 0; dec.More(); i++ {
+			var v T
+			if err = dec.Decode(&v); err != nil {
+				var typeErr *json.UnmarshalTypeError
+				if errors.As(err, &typeErr) {
+					// the element is consumed, the next one can still be decoded.
+					if !yield(zero, &RecordError{Index: i, Offset: dec.InputOffset(), Err: err}) {
+						return
+					}
+					continue
+				}
+				yield(zero, err)
+				return
+			}
+			if !yield(v, nil) {
+				return
+			}
+		}
+		if _, err = dec.Token(); err != nil {
+			yield(zero, noEOF(err))
+		}
+	}
+}
+
+// noEOF converts io.EOF to io.ErrUnexpectedEOF, the document always ends too early when dec.Token returns io.EOF.
+func noEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// seekJSONPath consumes the tokens of dec until the value selected by segments is the next one.
+func seekJSONPath(dec *json.Decoder, segments []string) error {
+	for depth, seg := range segments {
+		at := strings.Join(segments[:depth], ".")
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		found := false
+		switch tok {
+		case json.Delim('{'):
+			for !found && dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				if key == seg {
+					found = true
+				} else if err = skipJSONValue(dec); err != nil {
+					return err
+				}
+			}
+		case json.Delim('['):
+			index, err := strconv.Atoi(seg)
+			if err != nil || index < 0 {
+				return fmt.Errorf("xio: value at %q is an array, %q is not an index", at, seg)
+			}
+			for i := 0; dec.More(); i++ {
+				if i == index {
+					found = true
+					break
+				}
+				if err = skipJSONValue(dec); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("xio: value at %q is %v, not an object or array", at, tok)
+		}
+		if !found {
+			return fmt.Errorf("xio: path %q not found", strings.Join(segments[:depth+1], "."))
+		}
+	}
+	return nil
+}
+
+// skipJSONValue consumes the next value of dec without decoding it.
+func skipJSONValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Package xio converts between encoded streams and xiter sequences,
+// such as JSON Lines, CSV and JSON arrays, without loading the whole stream into memory.
+//
+// The decoders return xiter.SeqErr, and a malformed record is reported by a *RecordError with its position,
+// the decoding goes on if the consumer keeps iterating, so both xiter.TryToSlice and xiter.CollectErrors work with them.
+// An error of the underlying io.Reader stops the iteration, DecodeJSONLines reports it by a *RecordError
+// with the line it fails at, and the other decoders yield it as it is.
+package xio
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/dashjay/xiter/xiter"
+)
+
+// RecordError is the error of decoding or encoding one record.
+type RecordError struct {
+	// Line is the 1-based line number of the record in the stream,
+	// for encoders it is the line the record would be written to. It is 0 for the elements of a JSON array.
+	Line int
+	// Index is the 0-based index of the element in a JSON array, and Offset is the byte offset
+	// where the element ends in the stream. They are only set by DecodeJSONArray and DecodeJSONArrayAt.
+	Index  int
+	Offset int64
+	// Column is the name of the column which fails, it is empty if the error is not about a column.
+	Column string
+	Err    error
+}
+
+func (e *RecordError) Error() string {
+	pos := fmt.Sprintf("line %d", e.Line)
+	if e.Line == 0 {
+		pos = fmt.Sprintf("element %d, offset %d", e.Index, e.Offset)
+	}
+	if e.Column != "" {
+		return fmt.Sprintf("%s, column %q: %v", pos, e.Column, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", pos, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeJSONLines returns a SeqErr over the values decoded from r in the JSON Lines format,
+// every non-blank line is decoded into a new T with json.Unmarshal, and blank lines are skipped.
+// The buffer for long lines is configured by xiter.WithReadBufferSize and xiter.WithMaxTokenSize.
+//
+// EXAMPLE:
+//
+//	type Event struct {
+//		ID   int    `json:"id"`
+//		Name string `json:"name"`
+//	}
+//	events, err := xiter.TryToSlice(xio.DecodeJSONLines[Event](strings.NewReader(`{"id":1,"name":"a"}
+//	{"id":2,"name":"b"}`)))
+//	events 👉 [{1 a} {2 b}]
+func DecodeJSONLines[T any](r io.Reader, opts ...xiter.ReaderOption) xiter.SeqErr[T] {
+	return func(yield func(T, error) bool) {
+		line := 0
+		xiter.Lines(r, opts...)(func(text string, err error) bool {
+			var v, zero T
+			if err != nil {
+				yield(zero, &RecordError{Line: line + 1, Err: err})
+				return false
+			}
+			line++
+			if isBlank(text) {
+				return true
+			}
+			if err = json.Unmarshal([]byte(text), &v); err != nil {
+				// v may be partly decoded, a failed record is always zero.
+				return yield(zero, &RecordError{Line: line, Err: err})
+			}
+			return yield(v, nil)
+		})
+	}
+}
+
+// EncodeJSONLines writes the values of seq to w in the JSON Lines format, one value per line.
+// It stops at the first error, which is a *RecordError if a value can not be encoded, or the error of w.
+//
+// EXAMPLE:
+//
+//	var buf bytes.Buffer
+//	_ = xio.EncodeJSONLines(&buf, xiter.FromSlice([]int{1, 2}))
+//	buf.String() 👉 "1\n2\n"
+func EncodeJSONLines[T any](w io.Writer, seq xiter.Seq[T]) (err error) {
+	line := 0
+	seq(func(v T) bool {
+		line++
+		data, e := json.Marshal(v)
+		if e != nil {
+			err = &RecordError{Line: line, Err: e}
+			return false
+		}
+		if _, err = w.Write(append(data, '\n')); err != nil {
+			return false
+		}
+		return true
+	})
+	return err
+}
+
+func isBlank(s string) bool {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case ' ', '\t', '\r', '\n':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,254 @@
+package xio_test
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dashjay/xiter/xio"
+	"github.com/dashjay/xiter/xiter"
+)
+
+type event struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type user struct {
+	Name    string    `csv:"name"`
+	Age     int       `csv:"age"`
+	Score   float64   `csv:"score"`
+	Active  bool      `csv:"active"`
+	Joined  time.Time `csv:"joined"`
+	Ignored string    `csv:"-"`
+	Plain   uint8
+	hidden  int
+}
+
+// failWriter fails after n writes.
+type failWriter struct {
+	n int
+}
+
+var errWrite = errors.New("write failed")
+
+func (w *failWriter) Write(p []byte) (int, error) {
+	if w.n <= 0 {
+		return 0, errWrite
+	}
+	w.n--
+	return len(p), nil
+}
+
+func TestXIO(t *testing.T) {
+	t.Run("json lines", func(t *testing.T) {
+		input := "{\"id\":1,\"name\":\"a\"}\n\n  \n{\"id\":2,\"name\":\"b\"}\r\n{\"id\":3"
+		values, errs := xiter.CollectErrors(xio.DecodeJSONLines[event](strings.NewReader(input)))
+		assert.Equal(t, []event{{1, "a"}, {2, "b"}}, values)
+		assert.Len(t, errs, 1)
+		var re *xio.RecordError
+		assert.True(t, errors.As(errs[0], &re))
+		assert.Equal(t, 5, re.Line)
+		assert.Contains(t, re.Error(), "line 5: ")
+
+		// the record with a field of the wrong type is yielded as zero, not partly decoded
+		var partial []event
+		xio.DecodeJSONLines[event](strings.NewReader("{\"name\":\"a\",\"id\":\"x\"}"))(func(v event, err error) bool {
+			assert.NotNil(t, err)
+			partial = append(partial, v)
+			return true
+		})
+		assert.Equal(t, []event{{}}, partial)
+
+		values, err := xiter.TryToSlice(xio.DecodeJSONLines[event](strings.NewReader("{\"id\":\"x\"}\n{\"id\":2}")))
+		assert.Len(t, values, 0)
+		assert.True(t, errors.As(err, &re))
+		assert.Equal(t, 1, re.Line)
+
+		values, err = xiter.TryToSlice(xio.DecodeJSONLines[event](strings.NewReader("{\"id\":1}\n"+strings.Repeat(" ", 100)),
+			xiter.WithMaxTokenSize(10)))
+		assert.Equal(t, []event{{ID: 1}}, values)
+		assert.True(t, errors.As(err, &re))
+		assert.Equal(t, 2, re.Line)
+		assert.True(t, errors.Is(err, bufio.ErrTooLong))
+
+		var buf bytes.Buffer
+		assert.Nil(t, xio.EncodeJSONLines(&buf, xiter.FromSlice([]event{{1, "a"}, {2, "b"}})))
+		assert.Equal(t, "{\"id\":1,\"name\":\"a\"}\n{\"id\":2,\"name\":\"b\"}\n", buf.String())
+		decoded, err := xiter.TryToSlice(xio.DecodeJSONLines[event](&buf))
+		assert.Nil(t, err)
+		assert.Equal(t, []event{{1, "a"}, {2, "b"}}, decoded)
+
+		err = xio.EncodeJSONLines(&buf, xiter.FromSlice([]any{1, make(chan int)}))
+		assert.True(t, errors.As(err, &re))
+		assert.Equal(t, 2, re.Line)
+		assert.Equal(t, errWrite, xio.EncodeJSONLines(&failWriter{n: 1}, xiter.Range(0, 10, 1)))
+	})
+
+	t.Run("read csv", func(t *testing.T) {
+		records, err := xiter.TryToSlice(xio.ReadCSV(strings.NewReader("a,b\n1,\"2\n3\"\n4\n")))
+		assert.Nil(t, err)
+		assert.Equal(t, [][]string{{"a", "b"}, {"1", "2\n3"}, {"4"}}, records)
+
+		records, err = xiter.TryToSlice(xio.ReadCSV(strings.NewReader("# comment\na;b\n"),
+			xio.WithComma(';'), xio.WithComment('#')))
+		assert.Nil(t, err)
+		assert.Equal(t, [][]string{{"a", "b"}}, records)
+
+		records, errs := xiter.CollectErrors(xio.ReadCSV(strings.NewReader("a,b\nc,d\"e\nf,g\n")))
+		assert.Equal(t, [][]string{{"a", "b"}, {"f", "g"}}, records)
+		assert.Len(t, errs, 1)
+		var re *xio.RecordError
+		assert.True(t, errors.As(errs[0], &re))
+		assert.Equal(t, 2, re.Line)
+
+		records, err = xiter.TryToSlice(xio.ReadCSV(strings.NewReader("a,b\nc,d\"e\n"), xio.WithLazyQuotes()))
+		assert.Nil(t, err)
+		assert.Equal(t, [][]string{{"a", "b"}, {"c", "d\"e"}}, records)
+
+		assert.Len(t, xiter.ToSlice(xiter.Limit(xiter.Seq2KeyToSeq(xiter.SeqErrToSeq2(
+			xio.ReadCSV(strings.NewReader("1\n2\n3\n")))), 2)), 2)
+	})
+
+	t.Run("decode csv", func(t *testing.T) {
+		input := "name,age,score,active,joined,Plain,extra\n" +
+			"alice,30,1.5,true,2024-01-02T00:00:00Z,7,x\n" +
+			"bob,,,,,\n" +
+			"carol,old,1,false,,\n" +
+			"dave,40\n"
+		values, errs := xiter.CollectErrors(xio.DecodeCSV[user](strings.NewReader(input)))
+		assert.Equal(t, []user{
+			{Name: "alice", Age: 30, Score: 1.5, Active: true, Joined: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Plain: 7},
+			{Name: "bob"},
+			{Name: "dave", Age: 40},
+		}, values)
+		assert.Len(t, errs, 1)
+		var re *xio.RecordError
+		assert.True(t, errors.As(errs[0], &re))
+		assert.Equal(t, 4, re.Line)
+		assert.Equal(t, "age", re.Column)
+		assert.True(t, errors.Is(errs[0], strconv.ErrSyntax))
+		assert.Contains(t, re.Error(), `line 4, column "age": `)
+
+		values, err := xiter.TryToSlice(xio.DecodeCSV[user](strings.NewReader("")))
+		assert.Nil(t, err)
+		assert.Len(t, values, 0)
+
+		_, err = xiter.TryToSlice(xio.DecodeCSV[int](strings.NewReader("a\n1\n")))
+		assert.NotNil(t, err)
+		_, err = xiter.TryToSlice(xio.DecodeCSV[struct{ C chan int }](strings.NewReader("C\n1\n")))
+		assert.NotNil(t, err)
+	})
+
+	t.Run("write csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.Nil(t, xio.WriteCSV(&buf, xiter.FromSlice([][]string{{"a", "b"}, {"1", "2,3"}})))
+		assert.Equal(t, "a,b\n1,\"2,3\"\n", buf.String())
+
+		buf.Reset()
+		assert.Nil(t, xio.WriteCSV(&buf, xiter.FromSlice([][]string{{"a", "b"}}), xio.WithComma('\t'), xio.WithCRLF()))
+		assert.Equal(t, "a\tb\r\n", buf.String())
+
+		assert.Equal(t, errWrite, xio.WriteCSV(&failWriter{}, xiter.FromSlice([][]string{{"a"}})))
+
+		users := []user{
+			{Name: "alice", Age: 30, Score: 1.5, Active: true, Joined: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Plain: 7},
+			{Name: "bob", Ignored: "x"},
+		}
+		buf.Reset()
+		assert.Nil(t, xio.EncodeCSV(&buf, xiter.FromSlice(users)))
+		assert.Equal(t, "name,age,score,active,joined,Plain\n"+
+			"alice,30,1.5,true,2024-01-02T00:00:00Z,7\n"+
+			"bob,0,0,false,0001-01-01T00:00:00Z,0\n", buf.String())
+		decoded, err := xiter.TryToSlice(xio.DecodeCSV[user](&buf))
+		assert.Nil(t, err)
+		users[1].Ignored = ""
+		assert.Equal(t, users, decoded)
+
+		assert.NotNil(t, xio.EncodeCSV(&buf, xiter.FromSlice([]int{1})))
+		err = xio.EncodeCSV(&buf, xiter.FromSlice([]struct{ C chan int }{{}}))
+		var re *xio.RecordError
+		assert.True(t, errors.As(err, &re))
+		assert.Equal(t, 2, re.Line)
+		assert.Equal(t, "C", re.Column)
+	})
+	t.Run("json array", func(t *testing.T) {
+		values, err := xiter.TryToSlice(xio.DecodeJSONArray[int](strings.NewReader(" [1, 2, 3] ")))
+		assert.Nil(t, err)
+		assert.Equal(t, []int{1, 2, 3}, values)
+
+		for _, input := range []string{"[]", "null"} {
+			values, err = xiter.TryToSlice(xio.DecodeJSONArray[int](strings.NewReader(input)))
+			assert.Nil(t, err)
+			assert.Len(t, values, 0)
+		}
+
+		events, err := xiter.TryToSlice(xio.DecodeJSONArray[event](strings.NewReader(`[{"id":1,"name":"a"},{"id":2}]`)))
+		assert.Nil(t, err)
+		assert.Equal(t, []event{{1, "a"}, {2, ""}}, events)
+
+		values, errs := xiter.CollectErrors(xio.DecodeJSONArray[int](strings.NewReader(`[1, "x", {"a": [2]}, 4]`)))
+		assert.Equal(t, []int{1, 4}, values)
+		assert.Len(t, errs, 2)
+		var re *xio.RecordError
+		if assert.True(t, errors.As(errs[0], &re)) {
+			assert.Equal(t, 1, re.Index)
+			assert.Equal(t, int64(7), re.Offset)
+			assert.Contains(t, re.Error(), "element 1, offset 7: ")
+		}
+		if assert.True(t, errors.As(errs[1], &re)) {
+			assert.Equal(t, 2, re.Index)
+		}
+
+		for _, input := range []string{"", "[1, 2", "[1, 2,", "{}", "1", "[1 2]"} {
+			_, err = xiter.TryToSlice(xio.DecodeJSONArray[int](strings.NewReader(input)))
+			assert.NotNil(t, err, input)
+		}
+		_, err = xiter.TryToSlice(xio.DecodeJSONArray[int](strings.NewReader("")))
+		assert.Equal(t, io.ErrUnexpectedEOF, err)
+
+		// the consumer stops early, the rest is never read.
+		values = xiter.ToSlice(xiter.Limit(xiter.Seq2KeyToSeq(xiter.SeqErrToSeq2(
+			xio.DecodeJSONArray[int](strings.NewReader("[1, 2, 3, oops")))), 2))
+		assert.Equal(t, []int{1, 2}, values)
+	})
+
+	t.Run("json array at", func(t *testing.T) {
+		doc := `{
+			"total": 3,
+			"meta": {"skip": [1, [2, {"items": [0]}]], "s": "x"},
+			"data": {"items": [{"id": 1}, {"id": 2}], "after": [}`
+		events, err := xiter.TryToSlice(xio.DecodeJSONArrayAt[event](strings.NewReader(doc), "data.items"))
+		assert.Nil(t, err)
+		assert.Equal(t, []event{{ID: 1}, {ID: 2}}, events)
+
+		pages := `{"pages": [{"items": [1]}, {"items": [2, 3]}]}`
+		values, err := xiter.TryToSlice(xio.DecodeJSONArrayAt[int](strings.NewReader(pages), "pages.1.items"))
+		assert.Nil(t, err)
+		assert.Equal(t, []int{2, 3}, values)
+
+		values, err = xiter.TryToSlice(xio.DecodeJSONArrayAt[int](strings.NewReader("[[1], [2, 3]]"), "1"))
+		assert.Nil(t, err)
+		assert.Equal(t, []int{2, 3}, values)
+
+		for path, msg := range map[string]string{
+			"pages.2.items":     `path "pages.2" not found`,
+			"pages.x":           `"x" is not an index`,
+			"missing":           `path "missing" not found`,
+			"pages.0.items.0.a": `value at "pages.0.items.0" is 1, not an object or array`,
+			"pages.0":           `value at "pages.0" is {, not an array`,
+		} {
+			_, err = xiter.TryToSlice(xio.DecodeJSONArrayAt[int](strings.NewReader(pages), path))
+			if assert.NotNil(t, err, path) {
+				assert.Contains(t, err.Error(), msg, path)
+			}
+		}
+	})
+}
@@ -6,7 +6,6 @@ package xiter
 import (
 	"iter"
 	"maps"
-	"math/rand"
 	"strings"
 
 	"github.com/dashjay/xiter/internal/constraints"
@@ -174,6 +173,7 @@ func Filter2[K, V any](f func(K, V) bool, seq Seq2[K, V]) Seq2[K, V] {
 //	// [1 2 3]
 func Limit[V any](seq Seq[V], n int) Seq[V] {
 	return func(yield func(V) bool) {
+		n := n
 		if n <= 0 {
 			return
 		}
@@ -193,6 +193,7 @@ func Limit[V any](seq Seq[V], n int) Seq[V] {
 // Like Limit but run with Seq2
 func Limit2[K, V any](seq Seq2[K, V], n int) Seq2[K, V] {
 	return func(yield func(K, V) bool) {
+		n := n
 		if n <= 0 {
 			return
 		}
@@ -825,6 +826,7 @@ func ToSliceN[T any](seq Seq[T], n int) (out []T) {
 // Skip return a seq that skip n elements from seq.
 func Skip[T any](seq Seq[T], n int) Seq[T] {
 	return func(yield func(T) bool) {
+		n := n
 		for v := range seq {
 			if n == 0 {
 				if !yield(v) {
@@ -846,6 +848,7 @@ func Skip[T any](seq Seq[T], n int) Seq[T] {
 //	// replacedSeq will yield: 1, 99, 3, 99, 4
 func Replace[T comparable](seq Seq[T], from, to T, n int) Seq[T] {
 	return func(yield func(T) bool) {
+		n := n
 		for v := range seq {
 			if n != 0 && v == from {
 				if !yield(to) {
@@ -873,14 +876,17 @@ func ReplaceAll[T comparable](seq Seq[T], from, to T) Seq[T] {
 }
 
 // FromSliceShuffle return a seq that shuffle the elements in the input slice.
+// The order is chosen once when FromSliceShuffle is called, use WithRand for a reproducible order.
 //
 // Example:
 //
 //	seq := FromSlice([]int{1, 2, 3, 4, 5})
 //	shuffledSeq := FromSliceShuffle(ToSlice(seq))
 //	// shuffledSeq will yield a shuffled sequence of 1, 2, 3, 4, 5
-func FromSliceShuffle[T any](in []T) Seq[T] {
-	randPerm := rand.Perm(len(in))
+//	seededSeq := FromSliceShuffle(ToSlice(seq), WithRand(rand.New(rand.NewSource(1))))
+//	// seededSeq will yield the same order for every run
+func FromSliceShuffle[T any](in []T, opts ...RandOption) Seq[T] {
+	randPerm := newRandOptions(opts).Perm(len(in))
 	return func(yield func(T) bool) {
 		for i := 0; i < len(randPerm); i++ {
 			if !yield(in[randPerm[i]]) {
@@ -905,12 +911,12 @@ func Chunk[T any](seq Seq[T], n int) Seq[[]T] {
 			tmp = append(tmp, v)
 			if len(tmp) == n {
 				if !yield(tmp) {
-					break
+					return
 				}
 				tmp = make([]T, 0, n)
 			}
 		}
-		if len(tmp) < n {
+		if len(tmp) > 0 {
 			yield(tmp)
 		}
 	}
@@ -1116,3 +1122,323 @@ func Compact[T comparable](in Seq[T]) Seq[T] {
 		}
 	}
 }
+
+// SeqErr is a sequence of elements which may fail, provided by an iterator-like function.
+// Each step yields either an element with a nil error, or a zero element with a non-nil error.
+// Adapters in this package stop on the first error: the error is yielded to the consumer and the iteration ends.
+type SeqErr[V any] iter.Seq2[V, error]
+
+// SeqToSeqErr converts a Seq to a SeqErr which never yields an error.
+func SeqToSeqErr[V any](seq Seq[V]) SeqErr[V] {
+	return func(yield func(V, error) bool) {
+		for v := range seq {
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Seq2ToSeqErr converts a Seq2 of value-error pairs to a SeqErr.
+//
+// Example:
+//
+//	seq2 := xiter.Map2(func(_ int, s string) (int, error) {
+//		return strconv.Atoi(s)
+//	}, xiter.FromSliceIdx([]string{"1", "2", "x"}))
+//	values, err := xiter.TryToSlice(xiter.Seq2ToSeqErr(seq2))
+//	// values is [1 2], err is the error returned by strconv.Atoi("x")
+func Seq2ToSeqErr[V any](seq Seq2[V, error]) SeqErr[V] {
+	return SeqErr[V](seq)
+}
+
+// SeqErrToSeq2 converts a SeqErr to a Seq2 of value-error pairs.
+func SeqErrToSeq2[V any](seq SeqErr[V]) Seq2[V, error] {
+	return Seq2[V, error](seq)
+}
+
+// MapErr returns a SeqErr over the results of applying f to each value in seq.
+// It stops after the first error, whether it comes from seq or from f.
+//
+// Example:
+//
+//	seq := xiter.SeqToSeqErr(xiter.FromSlice([]string{"1", "2", "x", "4"}))
+//	values, err := xiter.TryToSlice(xiter.MapErr(strconv.Atoi, seq))
+//	// values is [1 2], err is the error returned by strconv.Atoi("x")
+func MapErr[In, Out any](f func(In) (Out, error), seq SeqErr[In]) SeqErr[Out] {
+	return func(yield func(Out, error) bool) {
+		var zero Out
+		for in, err := range seq {
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			out, err := f(in)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			if !yield(out, nil) {
+				return
+			}
+		}
+	}
+}
+
+// FilterErr returns a SeqErr over seq that only includes the values v for which f(v) is true.
+// It stops after the first error, whether it comes from seq or from f.
+//
+// Example:
+//
+//	seq := xiter.SeqToSeqErr(xiter.FromSlice([]int{1, 2, 3, 4}))
+//	even, _ := xiter.TryToSlice(xiter.FilterErr(func(v int) (bool, error) {
+//		return v%2 == 0, nil
+//	}, seq))
+//	// even is [2 4]
+func FilterErr[V any](f func(V) (bool, error), seq SeqErr[V]) SeqErr[V] {
+	return func(yield func(V, error) bool) {
+		var zero V
+		for v, err := range seq {
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			ok, err := f(v)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			if ok && !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
+
+// TryReduce combines the values in seq using f like Reduce.
+// It returns the sum accumulated so far and the first error from seq or f.
+//
+// Example:
+//
+//	seq := xiter.SeqToSeqErr(xiter.FromSlice([]int{1, 2, 3}))
+//	sum, err := xiter.TryReduce(func(sum int, v int) (int, error) {
+//		return sum + v, nil
+//	}, 0, seq)
+//	// sum is 6, err is nil
+func TryReduce[Sum, V any](f func(Sum, V) (Sum, error), sum Sum, seq SeqErr[V]) (Sum, error) {
+	for v, err := range seq {
+		if err != nil {
+			return sum, err
+		}
+		next, err := f(sum, v)
+		if err != nil {
+			return sum, err
+		}
+		sum = next
+	}
+	return sum, nil
+}
+
+// TryToSlice returns the elements in seq as a slice, it stops at the first error
+// and returns the elements collected before it.
+func TryToSlice[V any](seq SeqErr[V]) (out []V, err error) {
+	for v, e := range seq {
+		if e != nil {
+			return out, e
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// CollectErrors iterates the whole seq and returns all the elements and all the errors it yields.
+// Unlike TryToSlice, it does not stop at the first error.
+//
+// Example:
+//
+//	seq := xiter.Seq2ToSeqErr(xiter.Map2(func(_ int, s string) (int, error) {
+//		return strconv.Atoi(s)
+//	}, xiter.FromSliceIdx([]string{"1", "x", "3", "y"})))
+//	values, errs := xiter.CollectErrors(seq)
+//	// values is [1 3], errs contains 2 errors
+func CollectErrors[V any](seq SeqErr[V]) (out []V, errs []error) {
+	for v, err := range seq {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		out = append(out, v)
+	}
+	return out, errs
+}
+
+// TakeWhile returns a Seq over the leading values v of seq for which f(v) is true,
+// it stops at the first value for which f(v) is false.
+//
+// Example:
+//
+//	seq := xiter.FromSlice([]int{1, 2, 3, 1, 2})
+//	fmt.Println(xiter.ToSlice(xiter.TakeWhile(func(v int) bool { return v < 3 }, seq)))
+//	// output:
+//	// [1 2]
+func TakeWhile[V any](f func(V) bool, seq Seq[V]) Seq[V] {
+	return func(yield func(V) bool) {
+		for v := range seq {
+			if !f(v) || !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// TakeWhile2 returns a Seq2 over the leading key-value pairs of seq for which f(k, v) is true.
+// Like TakeWhile but run with Seq2
+func TakeWhile2[K, V any](f func(K, V) bool, seq Seq2[K, V]) Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, v := range seq {
+			if !f(k, v) || !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// TakeUntil returns a Seq over the values of seq up to and including the first value v for which f(v) is true.
+//
+// Example:
+//
+//	seq := xiter.FromSlice([]string{"a", "b", "END", "c"})
+//	fmt.Println(xiter.ToSlice(xiter.TakeUntil(func(v string) bool { return v == "END" }, seq)))
+//	// output:
+//	// [a b END]
+func TakeUntil[V any](f func(V) bool, seq Seq[V]) Seq[V] {
+	return func(yield func(V) bool) {
+		for v := range seq {
+			if !yield(v) || f(v) {
+				return
+			}
+		}
+	}
+}
+
+// TakeUntil2 returns a Seq2 over the key-value pairs of seq up to and including the first pair for which f(k, v) is true.
+// Like TakeUntil but run with Seq2
+func TakeUntil2[K, V any](f func(K, V) bool, seq Seq2[K, V]) Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, v := range seq {
+			if !yield(k, v) || f(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// DropWhile returns a Seq over seq that skips the leading values v for which f(v) is true,
+// all values from the first one for which f(v) is false are yielded.
+//
+// Example:
+//
+//	seq := xiter.FromSlice([]int{1, 2, 3, 1, 2})
+//	fmt.Println(xiter.ToSlice(xiter.DropWhile(func(v int) bool { return v < 3 }, seq)))
+//	// output:
+//	// [3 1 2]
+func DropWhile[V any](f func(V) bool, seq Seq[V]) Seq[V] {
+	return func(yield func(V) bool) {
+		dropping := true
+		for v := range seq {
+			if dropping && f(v) {
+				continue
+			}
+			dropping = false
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// DropWhile2 returns a Seq2 over seq that skips the leading key-value pairs for which f(k, v) is true.
+// Like DropWhile but run with Seq2
+func DropWhile2[K, V any](f func(K, V) bool, seq Seq2[K, V]) Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		dropping := true
+		for k, v := range seq {
+			if dropping && f(k, v) {
+				continue
+			}
+			dropping = false
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// SkipWhile is an alias of DropWhile.
+//
+// Example:
+//
+//	seq := xiter.FromSlice([]int{1, 2, 3, 1, 2})
+//	fmt.Println(xiter.ToSlice(xiter.SkipWhile(func(v int) bool { return v < 3 }, seq)))
+//	// output:
+//	// [3 1 2]
+func SkipWhile[V any](f func(V) bool, seq Seq[V]) Seq[V] {
+	return DropWhile(f, seq)
+}
+
+// SkipWhile2 is an alias of DropWhile2.
+func SkipWhile2[K, V any](f func(K, V) bool, seq Seq2[K, V]) Seq2[K, V] {
+	return DropWhile2(f, seq)
+}
+
+// SplitAt splits seq into a prefix of its first n values and the rest, seq is iterated only once.
+//
+// seq is pulled when prefix or rest is first iterated, so both of them can be iterated only once.
+// rest yields the values after the first n ones, and the values of prefix which are not consumed yet are dropped,
+// so rest should be iterated after prefix. stop releases seq, it is called automatically when rest ends,
+// call it (typically with defer) in case rest is not iterated to the end.
+//
+// Example:
+//
+//	prefix, rest, stop := xiter.SplitAt(xiter.FromSlice([]int{1, 2, 3, 4, 5}), 2)
+//	defer stop()
+//	fmt.Println(xiter.ToSlice(prefix), xiter.ToSlice(rest))
+//	// output:
+//	// [1 2] [3 4 5]
+func SplitAt[V any](seq Seq[V], n int) (prefix Seq[V], rest Seq[V], stop func()) {
+	prefix2, rest2, stop := SplitAt2(seqToKeySeq2(seq), n)
+	return Seq2KeyToSeq(prefix2), Seq2KeyToSeq(rest2), stop
+}
+
+// SplitAt2 splits seq into a prefix of its first n key-value pairs and the rest.
+// Like SplitAt but run with Seq2
+func SplitAt2[K, V any](seq Seq2[K, V], n int) (prefix Seq2[K, V], rest Seq2[K, V], stop func()) {
+	i := 0
+	return split2(seq, func(K, V) bool {
+		i++
+		return i <= n
+	})
+}
+
+// Span splits seq into a prefix of its leading values v for which f(v) is true and the rest,
+// seq is iterated only once. Like SplitAt, prefix and rest can be iterated only once.
+//
+// Example:
+//
+//	prefix, rest, stop := xiter.Span(func(v int) bool { return v < 3 }, xiter.FromSlice([]int{1, 2, 3, 1}))
+//	defer stop()
+//	fmt.Println(xiter.ToSlice(prefix), xiter.ToSlice(rest))
+//	// output:
+//	// [1 2] [3 1]
+func Span[V any](f func(V) bool, seq Seq[V]) (prefix Seq[V], rest Seq[V], stop func()) {
+	prefix2, rest2, stop := Span2(func(v V, _ struct{}) bool {
+		return f(v)
+	}, seqToKeySeq2(seq))
+	return Seq2KeyToSeq(prefix2), Seq2KeyToSeq(rest2), stop
+}
+
+// Span2 splits seq into a prefix of its leading key-value pairs for which f(k, v) is true and the rest.
+// Like Span but run with Seq2
+func Span2[K, V any](f func(K, V) bool, seq Seq2[K, V]) (prefix Seq2[K, V], rest Seq2[K, V], stop func()) {
+	return split2(seq, f)
+}
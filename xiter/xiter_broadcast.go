@@ -0,0 +1,303 @@
+package xiter
+
+import (
+	"fmt"
+	"sync"
+)
+
+// BroadcastPolicy decides what Broadcast does when the buffer of a consumer is full.
+type BroadcastPolicy int
+
+const (
+	// BroadcastBlock blocks the producer until the slow consumer receives, so all consumers get all elements,
+	// but the fastest consumer runs at the speed of the slowest one.
+	BroadcastBlock BroadcastPolicy = iota
+	// BroadcastDropOldest drops the oldest buffered element of the slow consumer to make room for the new one.
+	BroadcastDropOldest
+	// BroadcastDropNewest drops the new element for the slow consumer, it keeps the buffered ones.
+	BroadcastDropNewest
+)
+
+// Broadcast returns n Seqs which receive the elements of seq from one producer goroutine,
+// every consumer has its own channel buffered with bufSize elements, and policy decides what happens
+// when a consumer falls behind by more than bufSize elements.
+// With a drop policy and bufSize 0, an element is only delivered to the consumers waiting for it.
+//
+// The producer starts when the first consumer starts iterating, so the consumers are supposed to run concurrently.
+// A consumer which stops early is detached and no longer receives or holds the producer back,
+// each Seq can be iterated only once, the next iterations yield nothing.
+//
+// stop makes the producer exit and all consumers stop. It does not wait for the producer goroutine,
+// so it never hangs on a seq blocked internally, the producer exits as soon as seq yields again or returns.
+// If seq panicked before stop is called, stop re-panics with the same value on the caller's goroutine,
+// a panic after it is re-panicked on the producer goroutine.
+// So stop must always be called, typically with defer, and it is safe to call it more than once.
+//
+// EXAMPLE:
+//
+//	seqs, stop := xiter.Broadcast(xiter.FromSlice([]int{1, 2, 3}), 2, 4, xiter.BroadcastBlock)
+//	defer stop()
+//	var wg sync.WaitGroup
+//	for _, seq := range seqs {
+//		wg.Add(1)
+//		go func(seq xiter.Seq[int]) {
+//			defer wg.Done()
+//			fmt.Println(xiter.ToSlice(seq))
+//		}(seq)
+//	}
+//	wg.Wait()
+//	// output:
+//	// [1 2 3]
+//	// [1 2 3]
+func Broadcast[T any](seq Seq[T], n int, bufSize int, policy BroadcastPolicy) ([]Seq[T], func()) {
+	mustBeNonNegative("n", n)
+	mustBeNonNegative("bufSize", bufSize)
+	if policy < BroadcastBlock || policy > BroadcastDropNewest {
+		panic(fmt.Sprintf("unknown broadcast policy %d", policy))
+	}
+	b := &broadcast[T]{
+		seq:       seq,
+		policy:    policy,
+		consumers: make([]*broadcastConsumer[T], 0, n),
+		quit:      make(chan struct{}),
+	}
+	seqs := make([]Seq[T], 0, n)
+	for i := 0; i < n; i++ {
+		c := &broadcastConsumer[T]{ch: make(chan T, bufSize), detached: make(chan struct{})}
+		b.consumers = append(b.consumers, c)
+		seqs = append(seqs, func(yield func(T) bool) {
+			b.consume(c, yield)
+		})
+	}
+	return seqs, b.stop
+}
+
+type broadcast[T any] struct {
+	seq       Seq[T]
+	policy    BroadcastPolicy
+	consumers []*broadcastConsumer[T]
+
+	mu         sync.Mutex
+	started    bool
+	stopped    bool
+	quit       chan struct{}
+	panicked   bool
+	panicValue any
+}
+
+type broadcastConsumer[T any] struct {
+	ch       chan T
+	detached chan struct{}
+	once     sync.Once
+}
+
+func (c *broadcastConsumer[T]) detach() {
+	c.once.Do(func() {
+		close(c.detached)
+	})
+}
+
+func (b *broadcast[T]) consume(c *broadcastConsumer[T], yield func(T) bool) {
+	select {
+	case <-c.detached:
+		return
+	default:
+	}
+	defer c.detach()
+	b.start()
+	for {
+		select {
+		case <-b.quit:
+			return
+		case v, ok := <-c.ch:
+			if !ok || isClosed(b.quit) || !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// start runs the producer goroutine once, unless stop has been called.
+func (b *broadcast[T]) start() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.started || b.stopped {
+		return
+	}
+	b.started = true
+	go b.produce()
+}
+
+func (b *broadcast[T]) produce() {
+	defer func() {
+		for _, c := range b.consumers {
+			close(c.ch)
+		}
+	}()
+	defer func() {
+		if p := recover(); p != nil {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if b.stopped {
+				panic(p)
+			}
+			b.panicked = true
+			b.panicValue = p
+		}
+	}()
+	b.seq(func(v T) bool {
+		alive := false
+		for _, c := range b.consumers {
+			if b.send(c, v) {
+				alive = true
+			}
+			if isClosed(b.quit) {
+				return false
+			}
+		}
+		// all consumers are detached, nobody needs more elements.
+		return alive
+	})
+}
+
+// send delivers v to c by the policy, it returns false if c is detached.
+func (b *broadcast[T]) send(c *broadcastConsumer[T], v T) bool {
+	select {
+	case <-c.detached:
+		return false
+	default:
+	}
+	switch b.policy {
+	case BroadcastBlock:
+		select {
+		case c.ch <- v:
+		case <-c.detached:
+			return false
+		case <-b.quit:
+		}
+	case BroadcastDropOldest:
+		for {
+			select {
+			case c.ch <- v:
+				return true
+			default:
+			}
+			select {
+			case <-c.ch:
+			default:
+				// bufSize is 0 and c is not waiting, there is nothing to drop.
+				if cap(c.ch) == 0 {
+					return true
+				}
+			}
+		}
+	case BroadcastDropNewest:
+		select {
+		case c.ch <- v:
+		default:
+		}
+	}
+	return true
+}
+
+func (b *broadcast[T]) stop() {
+	b.mu.Lock()
+	if b.stopped {
+		b.mu.Unlock()
+		return
+	}
+	b.stopped = true
+	close(b.quit)
+	panicked, panicValue := b.panicked, b.panicValue
+	b.mu.Unlock()
+	// the producer still inside seq is not waited for, it exits at the next element.
+	if panicked {
+		panic(panicValue)
+	}
+}
+
+// FanIn returns a Seq which merges the elements of seqs concurrently, every seq runs on its own goroutine,
+// and the elements are yielded in the order they are produced.
+//
+// When the consumer stops iterating, the goroutines are told to stop but not waited for, so a seq blocked
+// on a quiet source does not block the consumer, and its goroutine exits when the seq yields again or returns.
+// If any seq panics, the iteration stops and the panic is propagated to the consumer,
+// a panic after the consumer stops is re-panicked on the goroutine of the seq.
+//
+// EXAMPLE:
+//
+//	seq := xiter.FanIn(xiter.FromSlice([]int{1, 2}), xiter.FromSlice([]int{3, 4}))
+//	xiter.Sort(seq) 👉 [1 2 3 4]
+func FanIn[T any](seqs ...Seq[T]) Seq[T] {
+	return func(yield func(T) bool) {
+		ch := make(chan T)
+		done := make(chan struct{})
+		failed := make(chan struct{})
+		// mu makes a panic of a seq either recorded before the consumer stops, or re-panicked by its goroutine.
+		var mu sync.Mutex
+		var stopped bool
+		var panicValue any
+		var wg sync.WaitGroup
+		for _, seq := range seqs {
+			wg.Add(1)
+			go func(seq Seq[T]) {
+				defer wg.Done()
+				defer func() {
+					if p := recover(); p != nil {
+						mu.Lock()
+						defer mu.Unlock()
+						if stopped {
+							panic(p)
+						}
+						if !isClosed(failed) {
+							panicValue = p
+							close(failed)
+						}
+					}
+				}()
+				seq(func(v T) bool {
+					select {
+					case ch <- v:
+						return true
+					case <-done:
+						return false
+					}
+				})
+			}(seq)
+		}
+		go func() {
+			wg.Wait()
+			close(ch)
+		}()
+		defer func() {
+			mu.Lock()
+			stopped = true
+			mu.Unlock()
+			// the goroutines still inside their seqs are not waited for, they exit at the next element.
+			close(done)
+			if isClosed(failed) {
+				panic(panicValue)
+			}
+		}()
+		for {
+			select {
+			case <-failed:
+				return
+			case v, ok := <-ch:
+				if !ok || !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// isClosed reports whether ch is closed without blocking.
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
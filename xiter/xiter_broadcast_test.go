@@ -0,0 +1,201 @@
+package xiter_test
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dashjay/xiter/xiter"
+)
+
+func TestXIterBroadcast(t *testing.T) {
+	// collect iterates every seq on its own goroutine and returns their elements.
+	collect := func(seqs []xiter.Seq[int]) [][]int {
+		var wg sync.WaitGroup
+		results := make([][]int, len(seqs))
+		for i := range seqs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i] = xiter.ToSlice(seqs[i])
+			}(i)
+		}
+		wg.Wait()
+		return results
+	}
+
+	t.Run("broadcast block", func(t *testing.T) {
+		seqs, stop := xiter.Broadcast(xiter.Range(0, 1000, 1), 3, 4, xiter.BroadcastBlock)
+		assert.Len(t, seqs, 3)
+		for _, res := range collect(seqs) {
+			assert.Equal(t, _range(0, 1000), res)
+		}
+		stop()
+		stop()
+		// each seq can be iterated only once
+		assert.Len(t, xiter.ToSlice(seqs[0]), 0)
+
+		seqs, stop = xiter.Broadcast(xiter.Range(0, 10, 1), 0, 0, xiter.BroadcastBlock)
+		assert.Len(t, seqs, 0)
+		stop()
+
+		assert.Panics(t, func() { xiter.Broadcast(xiter.Range(0, 10, 1), -1, 0, xiter.BroadcastBlock) })
+		assert.Panics(t, func() { xiter.Broadcast(xiter.Range(0, 10, 1), 1, -1, xiter.BroadcastBlock) })
+		assert.Panics(t, func() { xiter.Broadcast(xiter.Range(0, 10, 1), 1, 0, xiter.BroadcastPolicy(100)) })
+	})
+
+	t.Run("broadcast early break", func(t *testing.T) {
+		ng := stableNumGoroutine()
+		seqs, stop := xiter.Broadcast(xiter.Range(0, 1000, 1), 2, 0, xiter.BroadcastBlock)
+		var wg sync.WaitGroup
+		var head, all []int
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			head = xiter.ToSlice(xiter.Limit(seqs[0], 3))
+		}()
+		go func() {
+			defer wg.Done()
+			all = xiter.ToSlice(seqs[1])
+		}()
+		wg.Wait()
+		assert.Equal(t, _range(0, 3), head)
+		assert.Equal(t, _range(0, 1000), all)
+		stop()
+		assert.Equal(t, ng, stableNumGoroutine())
+
+		// the producer stops when all consumers are detached
+		ng = stableNumGoroutine()
+		seqs, stop = xiter.Broadcast(xiter.Range(0, 1<<62, 1), 2, 0, xiter.BroadcastBlock)
+		for _, res := range collect([]xiter.Seq[int]{xiter.Limit(seqs[0], 5), xiter.Limit(seqs[1], 10)}) {
+			assert.NotEmpty(t, res)
+		}
+		stop()
+		assert.Equal(t, ng, stableNumGoroutine())
+	})
+
+	t.Run("broadcast stop", func(t *testing.T) {
+		ng := stableNumGoroutine()
+		seqs, stop := xiter.Broadcast(xiter.Range(0, 1<<62, 1), 2, 2, xiter.BroadcastBlock)
+		done := make(chan struct{})
+		var got []int
+		go func() {
+			defer close(done)
+			// seqs[1] is never iterated, the producer blocks on it
+			got = xiter.ToSlice(seqs[0])
+		}()
+		time.Sleep(20 * time.Millisecond)
+		stop()
+		<-done
+		assert.Equal(t, _range(0, len(got)), got)
+		assert.Len(t, xiter.ToSlice(seqs[1]), 0)
+		assert.Equal(t, ng, stableNumGoroutine())
+
+		// stop before iterating does not start the producer
+		seqs, stop = xiter.Broadcast(xiter.Range(0, 10, 1), 1, 0, xiter.BroadcastBlock)
+		stop()
+		assert.Len(t, xiter.ToSlice(seqs[0]), 0)
+	})
+
+	t.Run("broadcast blocked source", func(t *testing.T) {
+		in := make(chan int)
+		seqs, stop := xiter.Broadcast(xiter.FromChan(in), 1, 0, xiter.BroadcastBlock)
+		done := make(chan []int)
+		go func() {
+			done <- xiter.ToSlice(seqs[0])
+		}()
+		in <- 1
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			stop()
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Fatal("stop blocks on the producer blocked in seq")
+		}
+		assert.LessOrEqual(t, len(<-done), 1)
+		// the producer exits once seq returns.
+		close(in)
+	})
+
+	t.Run("broadcast drop", func(t *testing.T) {
+		for _, policy := range []xiter.BroadcastPolicy{xiter.BroadcastDropOldest, xiter.BroadcastDropNewest} {
+			produced := make(chan struct{})
+			seq := xiter.Seq[int](func(yield func(int) bool) {
+				defer close(produced)
+				xiter.Range(0, 100, 1)(yield)
+			})
+			seqs, stop := xiter.Broadcast(seq, 1, 4, policy)
+			// the slow consumer holds the first received element until all elements are sent,
+			// then gets the 4 buffered ones.
+			slow := xiter.ToSlice(xiter.Filter(func(v int) bool {
+				<-produced
+				return true
+			}, seqs[0]))
+			stop()
+			assert.Len(t, slow, 5)
+			assert.True(t, sort.IntsAreSorted(slow))
+			if policy == xiter.BroadcastDropOldest {
+				assert.Equal(t, 99, slow[4])
+			} else {
+				assert.Equal(t, 0, slow[0])
+			}
+		}
+	})
+
+	t.Run("broadcast panic", func(t *testing.T) {
+		seqs, stop := xiter.Broadcast(xiter.Seq[int](func(yield func(int) bool) {
+			yield(1)
+			panic("boom")
+		}), 1, 1, xiter.BroadcastBlock)
+		assert.Equal(t, []int{1}, xiter.ToSlice(seqs[0]))
+		assert.PanicsWithValue(t, "boom", stop)
+		assert.NotPanics(t, stop)
+	})
+
+	t.Run("fan in", func(t *testing.T) {
+		seq := xiter.FanIn(xiter.Range(0, 100, 1), xiter.Range(100, 200, 1), xiter.Range(200, 300, 1))
+		assert.Equal(t, _range(0, 300), xiter.ToSlice(xiter.Sort(seq)))
+		assert.Equal(t, _range(0, 300), xiter.ToSlice(xiter.Sort(seq)))
+		assert.Len(t, xiter.ToSlice(xiter.FanIn[int]()), 0)
+
+		ng := stableNumGoroutine()
+		seq = xiter.FanIn(xiter.Range(0, 1<<62, 1), xiter.Generate(func() int { return -1 }))
+		testLimit(t, seq, 10)
+		assert.Len(t, xiter.ToSlice(xiter.Limit(seq, 100)), 100)
+		assert.Equal(t, ng, stableNumGoroutine())
+	})
+
+	t.Run("fan in blocked source", func(t *testing.T) {
+		in := make(chan int)
+		seq := xiter.FanIn(xiter.FromChan(in), xiter.Range(0, 10, 1))
+		done := make(chan []int)
+		go func() {
+			done <- xiter.ToSlice(xiter.Limit(seq, 5))
+		}()
+		select {
+		case got := <-done:
+			assert.Len(t, got, 5)
+		case <-time.After(time.Second):
+			t.Fatal("the consumer blocks on a source blocked in seq after it stops")
+		}
+		close(in)
+	})
+
+	t.Run("fan in panic", func(t *testing.T) {
+		ng := stableNumGoroutine()
+		seq := xiter.FanIn(xiter.Range(0, 1<<62, 1), xiter.Seq[int](func(yield func(int) bool) {
+			yield(-1)
+			panic("boom")
+		}))
+		assert.PanicsWithValue(t, "boom", func() {
+			xiter.ToSlice(seq)
+		})
+		assert.Equal(t, ng, stableNumGoroutine())
+	})
+}
@@ -0,0 +1,113 @@
+package xiter
+
+import (
+	"context"
+	"sync"
+)
+
+// ChanOption configures the channel created by ToChanOpts.
+type ChanOption func(*chanOptions)
+
+type chanOptions struct {
+	buffer int
+	ctx    context.Context
+}
+
+// WithBuffer sets the buffer size of the channel, default is 0 (unbuffered).
+// Non-positive n is ignored.
+func WithBuffer(n int) ChanOption {
+	return func(o *chanOptions) {
+		if n > 0 {
+			o.buffer = n
+		}
+	}
+}
+
+// WithChanContext makes the producer goroutine exit when ctx is done, the channel is closed then.
+func WithChanContext(ctx context.Context) ChanOption {
+	return func(o *chanOptions) {
+		o.ctx = ctx
+	}
+}
+
+func newChanOptions(opts []ChanOption) *chanOptions {
+	o := &chanOptions{ctx: context.Background()}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// ToChanOpts sends all elements of seq to a returned channel from a new goroutine,
+// the channel is closed when seq is exhausted, stop is called, or the context set by WithChanContext is done.
+//
+// Unlike ToChan, the producer never stays blocked on the channel: stop unblocks it even if nobody drains
+// the channel anymore. stop does not wait for the producer, so it never hangs on a seq blocked internally,
+// such as FromChan over an idle channel; the producer exits as soon as seq yields again or returns.
+// If seq panicked before stop is called, which closes the channel, stop re-panics with the same value
+// on the caller's goroutine. So stop must always be called, typically with defer, and it is safe to call it more than once.
+// A panic of seq after stop is called has nobody to report to, it is re-panicked on the producer goroutine
+// and crashes the program like a panic of any other goroutine.
+//
+// EXAMPLE:
+//
+//	ch, stop := xiter.ToChanOpts(xiter.Range(0, 100, 1), xiter.WithBuffer(10))
+//	defer stop()
+//	for v := range ch {
+//		if v == 3 {
+//			break // stop unblocks the producer
+//		}
+//	}
+func ToChanOpts[T any](seq Seq[T], opts ...ChanOption) (<-chan T, func()) {
+	o := newChanOptions(opts)
+	ch := make(chan T, o.buffer)
+	quit := make(chan struct{})
+	// mu makes a panic of seq either recorded before stop is called, or re-panicked by the producer.
+	var mu sync.Mutex
+	var stopped, panicked bool
+	var panicValue any
+
+	go func() {
+		defer close(ch)
+		defer func() {
+			if p := recover(); p != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				if stopped {
+					panic(p)
+				}
+				panicked = true
+				panicValue = p
+			}
+		}()
+		if ctxDone(o.ctx) {
+			return
+		}
+		seq(func(v T) bool {
+			select {
+			case ch <- v:
+				return true
+			case <-quit:
+				return false
+			case <-o.ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			mu.Lock()
+			stopped = true
+			p, v := panicked, panicValue
+			mu.Unlock()
+			// the producer still inside seq is not waited for, it returns at the next yield.
+			close(quit)
+			if p {
+				panic(v)
+			}
+		})
+	}
+	return ch, stop
+}
@@ -0,0 +1,97 @@
+package xiter_test
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/dashjay/xiter/xiter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXIterChan(t *testing.T) {
+	t.Run("to chan opts", func(t *testing.T) {
+		ch, stop := xiter.ToChanOpts(xiter.FromSlice(_range(0, 10)))
+		assert.Equal(t, _range(0, 10), xiter.ToSlice(xiter.FromChan(ch)))
+		stop()
+		stop()
+
+		filled := make(chan struct{})
+		ch, stop = xiter.ToChanOpts(xiter.Seq[int](func(yield func(int) bool) {
+			defer close(filled)
+			xiter.FromSlice(_range(0, 10))(yield)
+		}), xiter.WithBuffer(10))
+		defer stop()
+		assert.Equal(t, 10, cap(ch))
+		// the producer fills the buffer without any reader.
+		<-filled
+		assert.Equal(t, 10, len(ch))
+		assert.Equal(t, _range(0, 10), xiter.ToSlice(xiter.FromChan(ch)))
+	})
+
+	t.Run("to chan opts stop", func(t *testing.T) {
+		ng := stableNumGoroutine()
+		ch, stop := xiter.ToChanOpts(xiter.Generate(func() int { return 1 }))
+		assert.Equal(t, 1, <-ch)
+		stop()
+		for range ch {
+		}
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, ng, runtime.NumGoroutine())
+	})
+
+	t.Run("to chan opts panic", func(t *testing.T) {
+		ch, stop := xiter.ToChanOpts(xiter.Seq[int](func(yield func(int) bool) {
+			yield(1)
+			panic("boom")
+		}))
+		assert.Equal(t, []int{1}, xiter.ToSlice(xiter.FromChan(ch)))
+		assert.PanicsWithValue(t, "boom", stop)
+		assert.NotPanics(t, stop)
+	})
+
+	t.Run("to chan opts blocked seq", func(t *testing.T) {
+		in := make(chan int)
+		ch, stop := xiter.ToChanOpts(xiter.FromChan(in))
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			stop()
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("stop blocks on a seq blocked internally")
+		}
+		// the producer exits once seq returns.
+		close(in)
+		for range ch {
+		}
+	})
+
+	t.Run("to chan opts context", func(t *testing.T) {
+		ng := stableNumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+		ch, stop := xiter.ToChanOpts(xiter.Generate(func() int { return 1 }), xiter.WithChanContext(ctx), xiter.WithBuffer(2))
+		defer stop()
+		assert.Equal(t, 1, <-ch)
+		cancel()
+		for range ch {
+		}
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, ng, runtime.NumGoroutine())
+
+		ch, stop = xiter.ToChanOpts(xiter.FromSlice(_range(0, 10)), xiter.WithChanContext(ctx))
+		defer stop()
+		assert.Len(t, xiter.ToSlice(xiter.FromChan(ch)), 0)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch, stop := xiter.ToChanOpts(xiter.FromSlice(_range(0, 100)), xiter.WithChanContext(ctx), xiter.WithBuffer(4))
+		defer stop()
+		assert.Equal(t, _range(0, 100), xiter.ToSlice(xiter.FromChanCtx(ctx, ch)))
+	})
+}
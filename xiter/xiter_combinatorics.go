@@ -0,0 +1,370 @@
+package xiter
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// Product returns a Seq over the cartesian product of pools, like nested for-loops over them.
+// Each yielded product is a new slice which has one element from every pool, the rightmost element advances first.
+// If any pool is empty, it yields nothing, and if there is no pool, it yields one empty slice.
+// Use ProductCount to know the number of products before iterating.
+//
+// EXAMPLE:
+//
+//	xiter.Product([]int{1, 2}, []int{3, 4}) 👉 [[1 3] [1 4] [2 3] [2 4]]
+func Product[T any](pools ...[]T) Seq[[]T] {
+	return product(pools, false)
+}
+
+// ProductInPlace is like Product but yields the same underlying buffer for every product,
+// which is overwritten by the next one. The yielded slice must not be retained or modified.
+func ProductInPlace[T any](pools ...[]T) Seq[[]T] {
+	return product(pools, true)
+}
+
+// ProductCount returns the number of products yielded by Product with pools of the given sizes,
+// ok is false if the number overflows int.
+//
+// EXAMPLE:
+//
+//	xiter.ProductCount(2, 3, 4) 👉 24, true
+func ProductCount(sizes ...int) (count int, ok bool) {
+	count = 1
+	for _, size := range sizes {
+		mustBeNonNegative("size", size)
+		if count, ok = mulInt(count, size); !ok {
+			return 0, false
+		}
+	}
+	return count, true
+}
+
+// Permutations returns a Seq over all r-length permutations of the elements of in, in lexicographic order of positions.
+// Elements are treated as unique by their positions, not by their values.
+// It yields nothing if r > len(in), and panics if r is negative.
+//
+// EXAMPLE:
+//
+//	xiter.Permutations([]int{1, 2, 3}, 2) 👉 [[1 2] [1 3] [2 1] [2 3] [3 1] [3 2]]
+func Permutations[T any](in []T, r int) Seq[[]T] {
+	return permutations(in, r, false)
+}
+
+// PermutationsInPlace is like Permutations but yields the same underlying buffer for every permutation.
+// The yielded slice must not be retained or modified.
+func PermutationsInPlace[T any](in []T, r int) Seq[[]T] {
+	return permutations(in, r, true)
+}
+
+// PermutationsCount returns the number of permutations yielded by Permutations, n!/(n-r)!,
+// ok is false if the number overflows int.
+//
+// EXAMPLE:
+//
+//	xiter.PermutationsCount(5, 2) 👉 20, true
+func PermutationsCount(n, r int) (count int, ok bool) {
+	mustBeNonNegative("n", n)
+	mustBeNonNegative("r", r)
+	if r > n {
+		return 0, true
+	}
+	count = 1
+	for i := n - r + 1; i <= n; i++ {
+		if count, ok = mulInt(count, i); !ok {
+			return 0, false
+		}
+	}
+	return count, true
+}
+
+// Combinations returns a Seq over all r-length combinations of the elements of in, in lexicographic order of positions.
+// Elements are treated as unique by their positions, not by their values.
+// It yields nothing if r > len(in), and panics if r is negative.
+//
+// EXAMPLE:
+//
+//	xiter.Combinations([]int{1, 2, 3, 4}, 2) 👉 [[1 2] [1 3] [1 4] [2 3] [2 4] [3 4]]
+func Combinations[T any](in []T, r int) Seq[[]T] {
+	return combinations(in, r, false, false)
+}
+
+// CombinationsInPlace is like Combinations but yields the same underlying buffer for every combination.
+// The yielded slice must not be retained or modified.
+func CombinationsInPlace[T any](in []T, r int) Seq[[]T] {
+	return combinations(in, r, false, true)
+}
+
+// CombinationsCount returns the number of combinations yielded by Combinations, the binomial coefficient C(n, r),
+// ok is false if the number overflows int.
+//
+// EXAMPLE:
+//
+//	xiter.CombinationsCount(4, 2) 👉 6, true
+func CombinationsCount(n, r int) (count int, ok bool) {
+	mustBeNonNegative("n", n)
+	mustBeNonNegative("r", r)
+	if r > n {
+		return 0, true
+	}
+	return binomial(n, r)
+}
+
+// CombinationsWithReplacement returns a Seq over all r-length combinations of the elements of in,
+// allowing each element to be repeated. It yields nothing if in is empty and r is positive, and panics if r is negative.
+//
+// EXAMPLE:
+//
+//	xiter.CombinationsWithReplacement([]int{1, 2, 3}, 2) 👉 [[1 1] [1 2] [1 3] [2 2] [2 3] [3 3]]
+func CombinationsWithReplacement[T any](in []T, r int) Seq[[]T] {
+	return combinations(in, r, true, false)
+}
+
+// CombinationsWithReplacementInPlace is like CombinationsWithReplacement
+// but yields the same underlying buffer for every combination. The yielded slice must not be retained or modified.
+func CombinationsWithReplacementInPlace[T any](in []T, r int) Seq[[]T] {
+	return combinations(in, r, true, true)
+}
+
+// CombinationsWithReplacementCount returns the number of combinations yielded by CombinationsWithReplacement,
+// C(n+r-1, r), ok is false if the number overflows int.
+//
+// EXAMPLE:
+//
+//	xiter.CombinationsWithReplacementCount(3, 2) 👉 6, true
+func CombinationsWithReplacementCount(n, r int) (count int, ok bool) {
+	mustBeNonNegative("n", n)
+	mustBeNonNegative("r", r)
+	if n == 0 {
+		if r == 0 {
+			return 1, true
+		}
+		return 0, true
+	}
+	if n-1 > math.MaxInt-r {
+		return 0, false
+	}
+	return binomial(n+r-1, r)
+}
+
+// PowerSet returns a Seq over all subsets of the elements of in, ordered by size and then by positions,
+// the first one is the empty subset and the last one is in itself.
+//
+// EXAMPLE:
+//
+//	xiter.PowerSet([]int{1, 2, 3}) 👉 [[] [1] [2] [3] [1 2] [1 3] [2 3] [1 2 3]]
+func PowerSet[T any](in []T) Seq[[]T] {
+	return powerSet(in, false)
+}
+
+// PowerSetInPlace is like PowerSet but yields the same underlying buffer for every subset.
+// The yielded slice must not be retained or modified.
+func PowerSetInPlace[T any](in []T) Seq[[]T] {
+	return powerSet(in, true)
+}
+
+// PowerSetCount returns the number of subsets yielded by PowerSet, 2^n, ok is false if the number overflows int.
+//
+// EXAMPLE:
+//
+//	xiter.PowerSetCount(3) 👉 8, true
+//	xiter.PowerSetCount(100) 👉 0, false
+func PowerSetCount(n int) (count int, ok bool) {
+	mustBeNonNegative("n", n)
+	if n >= bits.UintSize-1 {
+		return 0, false
+	}
+	return 1 << n, true
+}
+
+// pick yields the elements of in at indices, with a reused buffer if inPlace or a new slice otherwise.
+func pick[T any](in []T, indices []int, buf []T, inPlace bool, yield func([]T) bool) bool {
+	out := buf
+	if !inPlace {
+		out = make([]T, len(indices))
+	}
+	for i, idx := range indices {
+		out[i] = in[idx]
+	}
+	return yield(out)
+}
+
+func product[T any](pools [][]T, inPlace bool) Seq[[]T] {
+	return func(yield func([]T) bool) {
+		for _, pool := range pools {
+			if len(pool) == 0 {
+				return
+			}
+		}
+		indices := make([]int, len(pools))
+		buf := make([]T, len(pools))
+		for {
+			out := buf
+			if !inPlace {
+				out = make([]T, len(pools))
+			}
+			for i, idx := range indices {
+				out[i] = pools[i][idx]
+			}
+			if !yield(out) {
+				return
+			}
+			// advance the rightmost index like an odometer.
+			i := len(indices) - 1
+			for ; i >= 0; i-- {
+				indices[i]++
+				if indices[i] < len(pools[i]) {
+					break
+				}
+				indices[i] = 0
+			}
+			if i < 0 {
+				return
+			}
+		}
+	}
+}
+
+func permutations[T any](in []T, r int, inPlace bool) Seq[[]T] {
+	mustBeNonNegative("r", r)
+	return func(yield func([]T) bool) {
+		n := len(in)
+		if r > n {
+			return
+		}
+		indices := make([]int, n)
+		for i := range indices {
+			indices[i] = i
+		}
+		cycles := make([]int, r)
+		for i := range cycles {
+			cycles[i] = n - i
+		}
+		buf := make([]T, r)
+		if !pick(in, indices[:r], buf, inPlace, yield) {
+			return
+		}
+		for {
+			i := r - 1
+			for ; i >= 0; i-- {
+				cycles[i]--
+				if cycles[i] > 0 {
+					j := n - cycles[i]
+					indices[i], indices[j] = indices[j], indices[i]
+					break
+				}
+				// rotate indices[i:] left by one and reset the cycle.
+				first := indices[i]
+				copy(indices[i:], indices[i+1:])
+				indices[n-1] = first
+				cycles[i] = n - i
+			}
+			if i < 0 {
+				return
+			}
+			if !pick(in, indices[:r], buf, inPlace, yield) {
+				return
+			}
+		}
+	}
+}
+
+func combinations[T any](in []T, r int, replacement, inPlace bool) Seq[[]T] {
+	mustBeNonNegative("r", r)
+	return func(yield func([]T) bool) {
+		n := len(in)
+		if (!replacement && r > n) || (replacement && n == 0 && r > 0) {
+			return
+		}
+		indices := make([]int, r)
+		if !replacement {
+			for i := range indices {
+				indices[i] = i
+			}
+		}
+		// maxAt returns the max index at position i.
+		maxAt := func(i int) int {
+			if replacement {
+				return n - 1
+			}
+			return i + n - r
+		}
+		buf := make([]T, r)
+		if !pick(in, indices, buf, inPlace, yield) {
+			return
+		}
+		for {
+			i := r - 1
+			for ; i >= 0 && indices[i] == maxAt(i); i-- {
+			}
+			if i < 0 {
+				return
+			}
+			indices[i]++
+			for j := i + 1; j < r; j++ {
+				if replacement {
+					indices[j] = indices[i]
+				} else {
+					indices[j] = indices[j-1] + 1
+				}
+			}
+			if !pick(in, indices, buf, inPlace, yield) {
+				return
+			}
+		}
+	}
+}
+
+func powerSet[T any](in []T, inPlace bool) Seq[[]T] {
+	return func(yield func([]T) bool) {
+		for r := 0; r <= len(in); r++ {
+			stopped := false
+			combinations(in, r, false, inPlace)(func(v []T) bool {
+				if !yield(v) {
+					stopped = true
+					return false
+				}
+				return true
+			})
+			if stopped {
+				return
+			}
+		}
+	}
+}
+
+// binomial returns C(n, r) for 0 <= r <= n, ok is false if it overflows int.
+func binomial(n, r int) (int, bool) {
+	if r > n-r {
+		r = n - r
+	}
+	var c uint64 = 1
+	for i := 0; i < r; i++ {
+		// c * (n-i) / (i+1) is always an integer, C(n, i+1), computed with a 128-bit product.
+		hi, lo := bits.Mul64(c, uint64(n-i))
+		d := uint64(i + 1)
+		if hi >= d {
+			return 0, false
+		}
+		c, _ = bits.Div64(hi, lo, d)
+	}
+	if c > math.MaxInt {
+		return 0, false
+	}
+	return int(c), true
+}
+
+// mulInt returns a * b for non-negative a and b, ok is false if it overflows int.
+func mulInt(a, b int) (int, bool) {
+	if a != 0 && b > math.MaxInt/a {
+		return 0, false
+	}
+	return a * b, true
+}
+
+func mustBeNonNegative(name string, v int) {
+	if v < 0 {
+		panic(fmt.Sprintf("%s %d must not be negative", name, v))
+	}
+}
@@ -0,0 +1,120 @@
+package xiter_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dashjay/xiter/xiter"
+)
+
+func TestXIterCombinatorics(t *testing.T) {
+	assertCount := func(t *testing.T, expected int, count int, ok bool) {
+		assert.True(t, ok)
+		assert.Equal(t, expected, count)
+	}
+
+	t.Run("product", func(t *testing.T) {
+		seq := xiter.Product([]int{1, 2}, []int{3, 4}, []int{5})
+		assert.Equal(t, [][]int{{1, 3, 5}, {1, 4, 5}, {2, 3, 5}, {2, 4, 5}}, xiter.ToSlice(seq))
+		testLimit(t, seq, 2)
+		assert.Equal(t, [][]int{{}}, xiter.ToSlice(xiter.Product[int]()))
+		assert.Len(t, xiter.ToSlice(xiter.Product([]int{1, 2}, []int{})), 0)
+
+		count, ok := xiter.ProductCount(2, 2, 1)
+		assertCount(t, 4, count, ok)
+		count, ok = xiter.ProductCount()
+		assertCount(t, 1, count, ok)
+		_, ok = xiter.ProductCount(math.MaxInt, 2)
+		assert.False(t, ok)
+		assert.Panics(t, func() { xiter.ProductCount(-1) })
+
+		var inPlace [][]int
+		xiter.ProductInPlace([]int{1, 2}, []int{3, 4})(func(v []int) bool {
+			inPlace = append(inPlace, v)
+			return true
+		})
+		assert.Len(t, inPlace, 4)
+		assert.Equal(t, []int{2, 4}, inPlace[0])
+	})
+
+	t.Run("permutations", func(t *testing.T) {
+		seq := xiter.Permutations([]int{1, 2, 3}, 2)
+		assert.Equal(t, [][]int{{1, 2}, {1, 3}, {2, 1}, {2, 3}, {3, 1}, {3, 2}}, xiter.ToSlice(seq))
+		testLimit(t, seq, 3)
+		assert.Equal(t, [][]int{{1, 2, 3}, {1, 3, 2}, {2, 1, 3}, {2, 3, 1}, {3, 1, 2}, {3, 2, 1}},
+			xiter.ToSlice(xiter.Permutations([]int{1, 2, 3}, 3)))
+		assert.Equal(t, [][]int{{}}, xiter.ToSlice(xiter.Permutations([]int{1, 2}, 0)))
+		assert.Len(t, xiter.ToSlice(xiter.Permutations([]int{1, 2}, 3)), 0)
+		assert.Panics(t, func() { xiter.Permutations([]int{1}, -1) })
+
+		for n := 0; n <= 6; n++ {
+			for r := 0; r <= n+1; r++ {
+				count, ok := xiter.PermutationsCount(n, r)
+				assertCount(t, xiter.Count(xiter.PermutationsInPlace(_range(0, n), r)), count, ok)
+			}
+		}
+		count, ok := xiter.PermutationsCount(20, 20)
+		assertCount(t, 2432902008176640000, count, ok)
+		_, ok = xiter.PermutationsCount(21, 21)
+		assert.False(t, ok)
+	})
+
+	t.Run("combinations", func(t *testing.T) {
+		seq := xiter.Combinations([]int{1, 2, 3, 4}, 2)
+		assert.Equal(t, [][]int{{1, 2}, {1, 3}, {1, 4}, {2, 3}, {2, 4}, {3, 4}}, xiter.ToSlice(seq))
+		testLimit(t, seq, 3)
+		assert.Equal(t, [][]int{{1, 2, 3}}, xiter.ToSlice(xiter.Combinations([]int{1, 2, 3}, 3)))
+		assert.Equal(t, [][]int{{}}, xiter.ToSlice(xiter.Combinations([]int{1, 2}, 0)))
+		assert.Len(t, xiter.ToSlice(xiter.Combinations([]int{1, 2}, 3)), 0)
+
+		for n := 0; n <= 8; n++ {
+			for r := 0; r <= n+1; r++ {
+				count, ok := xiter.CombinationsCount(n, r)
+				assertCount(t, xiter.Count(xiter.CombinationsInPlace(_range(0, n), r)), count, ok)
+			}
+		}
+		count, ok := xiter.CombinationsCount(66, 33)
+		assertCount(t, 7219428434016265740, count, ok)
+		_, ok = xiter.CombinationsCount(68, 34)
+		assert.False(t, ok)
+		count, ok = xiter.CombinationsCount(math.MaxInt, 1)
+		assertCount(t, math.MaxInt, count, ok)
+	})
+
+	t.Run("combinations with replacement", func(t *testing.T) {
+		seq := xiter.CombinationsWithReplacement([]int{1, 2, 3}, 2)
+		assert.Equal(t, [][]int{{1, 1}, {1, 2}, {1, 3}, {2, 2}, {2, 3}, {3, 3}}, xiter.ToSlice(seq))
+		testLimit(t, seq, 3)
+		assert.Equal(t, [][]int{{1, 1, 1}}, xiter.ToSlice(xiter.CombinationsWithReplacement([]int{1}, 3)))
+		assert.Len(t, xiter.ToSlice(xiter.CombinationsWithReplacement([]int{}, 2)), 0)
+		assert.Equal(t, [][]int{{}}, xiter.ToSlice(xiter.CombinationsWithReplacement([]int{}, 0)))
+
+		for n := 0; n <= 5; n++ {
+			for r := 0; r <= 5; r++ {
+				count, ok := xiter.CombinationsWithReplacementCount(n, r)
+				assertCount(t, xiter.Count(xiter.CombinationsWithReplacementInPlace(_range(0, n), r)), count, ok)
+			}
+		}
+		_, ok := xiter.CombinationsWithReplacementCount(2, math.MaxInt)
+		assert.False(t, ok)
+	})
+
+	t.Run("power set", func(t *testing.T) {
+		seq := xiter.PowerSet([]int{1, 2, 3})
+		assert.Equal(t, [][]int{{}, {1}, {2}, {3}, {1, 2}, {1, 3}, {2, 3}, {1, 2, 3}}, xiter.ToSlice(seq))
+		testLimit(t, seq, 3)
+		assert.Equal(t, [][]int{{}}, xiter.ToSlice(xiter.PowerSet([]int{})))
+
+		for n := 0; n <= 10; n++ {
+			count, ok := xiter.PowerSetCount(n)
+			assertCount(t, xiter.Count(xiter.PowerSetInPlace(_range(0, n))), count, ok)
+		}
+		_, ok := xiter.PowerSetCount(100)
+		assert.False(t, ok)
+
+		// lazy for a large set
+		assert.Equal(t, [][]int{{}, {0}, {1}}, xiter.ToSlice(xiter.Limit(xiter.PowerSet(_range(0, 100)), 3)))
+	})
+}
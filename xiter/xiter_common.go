@@ -1,9 +1,12 @@
 package xiter
 
 import (
+	"fmt"
+
 	"github.com/dashjay/xiter/internal/constraints"
 	gassert "github.com/dashjay/xiter/internal/xassert"
 	"github.com/dashjay/xiter/optional"
+	"github.com/dashjay/xiter/union"
 )
 
 // A Zipped2 is a pair of zipped key-value pairs,
@@ -178,6 +181,7 @@ func Union[T comparable](left, right Seq[T]) Seq[T] {
 }
 
 // Mean return the mean of seq.
+// The mean is computed in T, so the mean of integers is truncated, use AvgFromSeq or xstat.Mean for a float64 mean.
 //
 // EXAMPLE:
 //
@@ -282,6 +286,8 @@ func Generate[T any](fn func() T) Seq[T] {
 }
 
 // ToChan sends all elements of seq to a returned channel and closes it when the seq is exhausted.
+// The sending goroutine blocks forever if the channel is not drained, use ToChanOpts or ToChanCtx
+// when the reader may stop early.
 //
 // EXAMPLE:
 //
@@ -356,3 +362,345 @@ func WithIndex[T any](seq Seq[T]) Seq2[int, T] {
 		})
 	}
 }
+
+// Window returns a Seq of sliding windows over seq, each window contains size elements
+// and the start of two adjacent windows are step elements apart.
+// step == size gives tumbling windows, step > size skips elements between windows.
+// Trailing elements which can not fill a whole window are dropped.
+// Each yielded window is a new slice, see WindowInPlace for the zero-allocation version.
+// It panics if size or step is not positive.
+//
+// EXAMPLE:
+//
+//	seq := xiter.FromSlice([]int{1, 2, 3, 4, 5})
+//	xiter.ToSlice(xiter.Window(seq, 3, 1)) 👉 [[1 2 3] [2 3 4] [3 4 5]]
+//	xiter.ToSlice(xiter.Window(seq, 2, 2)) 👉 [[1 2] [3 4]]
+func Window[T any](seq Seq[T], size, step int) Seq[[]T] {
+	return window(seq, size, step, false)
+}
+
+// WindowInPlace is like Window but yields the same underlying buffer for every window,
+// the window is only valid until the next iteration and must be copied if retained.
+//
+// EXAMPLE:
+//
+//	seq := xiter.FromSlice([]int{1, 2, 3, 4, 5})
+//	xiter.WindowInPlace(seq, 3, 1)(func(w []int) bool {
+//		fmt.Println(w) // [1 2 3], [2 3 4], [3 4 5]
+//		return true
+//	})
+func WindowInPlace[T any](seq Seq[T], size, step int) Seq[[]T] {
+	return window(seq, size, step, true)
+}
+
+// Window2 returns a Seq of sliding windows over the key-value pairs of seq.
+// Like Window but run with Seq2
+func Window2[K, V any](seq Seq2[K, V], size, step int) Seq[[]union.U2[K, V]] {
+	return window(Seq2ToSeqUnion(seq), size, step, false)
+}
+
+// WindowInPlace2 is like WindowInPlace but run with Seq2
+func WindowInPlace2[K, V any](seq Seq2[K, V], size, step int) Seq[[]union.U2[K, V]] {
+	return window(Seq2ToSeqUnion(seq), size, step, true)
+}
+
+func window[T any](seq Seq[T], size, step int, inPlace bool) Seq[[]T] {
+	if size <= 0 || step <= 0 {
+		panic(fmt.Sprintf("window size %d and step %d must be positive", size, step))
+	}
+	return func(yield func([]T) bool) {
+		buf := make([]T, 0, size)
+		skip := 0
+		seq(func(v T) bool {
+			if skip > 0 {
+				skip--
+				return true
+			}
+			buf = append(buf, v)
+			if len(buf) < size {
+				return true
+			}
+			out := buf
+			if !inPlace {
+				out = append(make([]T, 0, size), buf...)
+			}
+			if !yield(out) {
+				return false
+			}
+			if step >= size {
+				buf = buf[:0]
+				skip = step - size
+			} else {
+				buf = buf[:copy(buf, buf[step:])]
+			}
+			return true
+		})
+	}
+}
+
+// Pairwise returns a Seq2 over the adjacent pairs of seq.
+// A seq with less than two elements yields nothing.
+//
+// EXAMPLE:
+//
+//	seq := xiter.FromSlice([]int{1, 2, 3})
+//	xiter.Pairwise(seq) 👉 (1, 2), (2, 3)
+func Pairwise[T any](seq Seq[T]) Seq2[T, T] {
+	return func(yield func(T, T) bool) {
+		var prev T
+		hasPrev := false
+		seq(func(v T) bool {
+			if !hasPrev {
+				prev, hasPrev = v, true
+				return true
+			}
+			if !yield(prev, v) {
+				return false
+			}
+			prev = v
+			return true
+		})
+	}
+}
+
+// Pairwise2 returns a Seq2 over the adjacent key-value pairs of seq.
+// Like Pairwise but run with Seq2
+func Pairwise2[K, V any](seq Seq2[K, V]) Seq2[union.U2[K, V], union.U2[K, V]] {
+	return Pairwise(Seq2ToSeqUnion(seq))
+}
+
+// ChunkBy groups consecutive elements of seq sharing the same key evaluated by f,
+// and yields each group with its key as soon as the key changes.
+// Only the current group is held in memory, so it is suitable for large streams sorted by the key.
+// The same key may appear more than once if the elements are not sorted by it.
+//
+// EXAMPLE:
+//
+//	seq := xiter.FromSlice([]int{1, 3, 2, 4, 5})
+//	odd := func(v int) bool { return v%2 == 1 }
+//	xiter.ChunkBy(seq, odd) 👉 (true, [1 3]), (false, [2 4]), (true, [5])
+func ChunkBy[T any, K comparable](seq Seq[T], f func(T) K) Seq2[K, []T] {
+	return func(yield func(K, []T) bool) {
+		var key K
+		var group []T
+		seq(func(v T) bool {
+			k := f(v)
+			if len(group) > 0 && k != key {
+				if !yield(key, group) {
+					group = nil
+					return false
+				}
+				group = nil
+			}
+			key = k
+			group = append(group, v)
+			return true
+		})
+		if len(group) > 0 {
+			yield(key, group)
+		}
+	}
+}
+
+// GroupBy groups all elements of seq by the key evaluated by f,
+// and yields the groups in the order their keys are first seen in seq.
+// Unlike xslice.GroupBy, the order of keys is kept, but the whole seq has to be read before the first group is yielded.
+//
+// EXAMPLE:
+//
+//	seq := xiter.FromSlice([]int{1, 2, 3, 4, 5})
+//	odd := func(v int) bool { return v%2 == 1 }
+//	xiter.GroupBy(seq, odd) 👉 (true, [1 3 5]), (false, [2 4])
+func GroupBy[T any, K comparable](seq Seq[T], f func(T) K) Seq2[K, []T] {
+	return func(yield func(K, []T) bool) {
+		var keys []K
+		groups := make(map[K][]T)
+		seq(func(v T) bool {
+			k := f(v)
+			if _, ok := groups[k]; !ok {
+				keys = append(keys, k)
+			}
+			groups[k] = append(groups[k], v)
+			return true
+		})
+		for _, k := range keys {
+			if !yield(k, groups[k]) {
+				return
+			}
+		}
+	}
+}
+
+// Scan returns a Seq over every intermediate result of Reduce,
+// for each value v in seq, it updates sum = f(sum, v) and yields sum.
+// For example, if iterating over seq yields v1, v2, v3,
+// Scan yields f(sum, v1), f(f(sum, v1), v2), f(f(f(sum, v1), v2), v3).
+//
+// EXAMPLE:
+//
+//	seq := xiter.FromSlice([]int{1, 2, 3, 4})
+//	xiter.Scan(func(sum int, v int) int { return sum * v }, 1, seq) 👉 [1 2 6 24]
+func Scan[Sum, V any](f func(Sum, V) Sum, sum Sum, seq Seq[V]) Seq[Sum] {
+	return func(yield func(Sum) bool) {
+		acc := sum
+		seq(func(v V) bool {
+			acc = f(acc, v)
+			return yield(acc)
+		})
+	}
+}
+
+// ScanWithIndex is like Scan, and yields the index of each value in seq along with the intermediate result.
+//
+// EXAMPLE:
+//
+//	seq := xiter.FromSlice([]int{1, 2, 3})
+//	xiter.ScanWithIndex(func(sum int, v int) int { return sum + v }, 0, seq) 👉 (0, 1) (1, 3) (2, 6)
+func ScanWithIndex[Sum, V any](f func(Sum, V) Sum, sum Sum, seq Seq[V]) Seq2[int, Sum] {
+	return func(yield func(int, Sum) bool) {
+		i := 0
+		Scan(f, sum, seq)(func(acc Sum) bool {
+			if !yield(i, acc) {
+				return false
+			}
+			i++
+			return true
+		})
+	}
+}
+
+// RunningSum returns a Seq over the cumulative sums of seq.
+//
+// EXAMPLE:
+//
+//	xiter.RunningSum(xiter.FromSlice([]int{1, 2, 3, 4})) 👉 [1 3 6 10]
+func RunningSum[T constraints.Number](seq Seq[T]) Seq[T] {
+	return Scan(func(sum T, v T) T {
+		return sum + v
+	}, 0, seq)
+}
+
+// RunningMax returns a Seq over the max value of seq seen so far.
+//
+// EXAMPLE:
+//
+//	xiter.RunningMax(xiter.FromSlice([]int{2, 1, 3, 2})) 👉 [2 2 3 3]
+func RunningMax[T constraints.Ordered](seq Seq[T]) Seq[T] {
+	return runningBest(seq, func(best T, v T) bool {
+		return v > best
+	})
+}
+
+// RunningMin returns a Seq over the min value of seq seen so far.
+//
+// EXAMPLE:
+//
+//	xiter.RunningMin(xiter.FromSlice([]int{2, 1, 3, 0})) 👉 [2 1 1 0]
+func RunningMin[T constraints.Ordered](seq Seq[T]) Seq[T] {
+	return runningBest(seq, func(best T, v T) bool {
+		return v < best
+	})
+}
+
+// RunningMean returns a Seq over the mean of the values of seq seen so far.
+//
+// EXAMPLE:
+//
+//	xiter.RunningMean(xiter.FromSlice([]int{1, 2, 3, 4})) 👉 [1 1.5 2 2.5]
+func RunningMean[T constraints.Number](seq Seq[T]) Seq[float64] {
+	return Map(func(acc meanAcc) float64 {
+		return acc.sum / float64(acc.count)
+	}, Scan(func(acc meanAcc, v T) meanAcc {
+		return meanAcc{sum: acc.sum + float64(v), count: acc.count + 1}
+	}, meanAcc{}, seq))
+}
+
+// meanAcc is the accumulator of RunningMean.
+type meanAcc struct {
+	sum   float64
+	count int
+}
+
+// runningBest yields the best value seen so far, v replaces best if better(best, v) is true.
+func runningBest[T any](seq Seq[T], better func(best T, v T) bool) Seq[T] {
+	return Map(func(acc optional.O[T]) T {
+		return acc.Must()
+	}, Scan(func(acc optional.O[T], v T) optional.O[T] {
+		if acc.Ok() && !better(acc.Must(), v) {
+			return acc
+		}
+		return optional.FromValue(v)
+	}, optional.Empty[T](), seq))
+}
+
+// split2 pulls seq once, the prefix yields pairs while f is true, the rest yields the pairs after it.
+func split2[K, V any](seq Seq2[K, V], f func(K, V) bool) (Seq2[K, V], Seq2[K, V], func()) {
+	var next func() (K, V, bool)
+	var stopPull func()
+	var stopped, prefixDone, pending bool
+	var pendingK K
+	var pendingV V
+
+	stop := func() {
+		if stopped {
+			return
+		}
+		stopped = true
+		if stopPull != nil {
+			stopPull()
+		}
+	}
+	// nextPrefix returns the next pair of the prefix, the first pair after the prefix is kept as pending.
+	nextPrefix := func() (k K, v V, ok bool) {
+		if stopped || prefixDone {
+			return k, v, false
+		}
+		if next == nil {
+			next, stopPull = Pull2(seq)
+		}
+		k, v, ok = next()
+		if ok && f(k, v) {
+			return k, v, true
+		}
+		prefixDone = true
+		if ok {
+			pending, pendingK, pendingV = true, k, v
+		}
+		var zeroK K
+		var zeroV V
+		return zeroK, zeroV, false
+	}
+
+	prefix := func(yield func(K, V) bool) {
+		for {
+			k, v, ok := nextPrefix()
+			if !ok || !yield(k, v) {
+				return
+			}
+		}
+	}
+	rest := func(yield func(K, V) bool) {
+		for {
+			if _, _, ok := nextPrefix(); !ok {
+				break
+			}
+		}
+		if stopped {
+			return
+		}
+		defer stop()
+		if pending {
+			pending = false
+			if !yield(pendingK, pendingV) {
+				return
+			}
+		}
+		for {
+			k, v, ok := next()
+			if !ok || !yield(k, v) {
+				return
+			}
+		}
+	}
+	return prefix, rest, stop
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/dashjay/xiter/union"
 	"github.com/dashjay/xiter/xiter"
 	"github.com/stretchr/testify/assert"
 )
@@ -202,4 +203,108 @@ func TestXIterCommon(t *testing.T) {
 			emptyIdx := xiter.WithIndex(xiter.FromSlice([]int{}))
 			assert.Len(t, xiter.ToSliceSeq2Key(emptyIdx), 0)
 		})
+
+	t.Run("window", func(t *testing.T) {
+		seq := xiter.FromSlice([]int{1, 2, 3, 4, 5})
+		assert.Equal(t, [][]int{{1, 2, 3}, {2, 3, 4}, {3, 4, 5}}, xiter.ToSlice(xiter.Window(seq, 3, 1)))
+		assert.Equal(t, [][]int{{1, 2}, {3, 4}}, xiter.ToSlice(xiter.Window(seq, 2, 2)))
+		assert.Equal(t, [][]int{{1, 2, 3}, {3, 4, 5}}, xiter.ToSlice(xiter.Window(seq, 3, 2)))
+		assert.Equal(t, [][]int{{1}, {4}}, xiter.ToSlice(xiter.Window(seq, 1, 3)))
+		assert.Len(t, xiter.ToSlice(xiter.Window(seq, 6, 1)), 0)
+		testLimit(t, xiter.Window(seq, 2, 1), 1)
+		assert.Panics(t, func() { xiter.Window(seq, 0, 1) })
+		assert.Panics(t, func() { xiter.Window(seq, 1, 0) })
+
+		var inPlace [][]int
+		xiter.WindowInPlace(seq, 3, 1)(func(w []int) bool {
+			inPlace = append(inPlace, append([]int(nil), w...))
+			return true
+		})
+		assert.Equal(t, [][]int{{1, 2, 3}, {2, 3, 4}, {3, 4, 5}}, inPlace)
+		windows := xiter.ToSlice(xiter.WindowInPlace(seq, 2, 2))
+		assert.Len(t, windows, 2)
+		assert.Equal(t, &windows[0][0], &windows[1][0])
+	})
+
+	t.Run("window2", func(t *testing.T) {
+		seq := xiter.FromSliceIdx([]string{"a", "b", "c"})
+		windows := xiter.ToSlice(xiter.Window2(seq, 2, 1))
+		assert.Equal(t, [][]union.U2[int, string]{
+			{{T1: 0, T2: "a"}, {T1: 1, T2: "b"}},
+			{{T1: 1, T2: "b"}, {T1: 2, T2: "c"}},
+		}, windows)
+		assert.Len(t, xiter.ToSlice(xiter.WindowInPlace2(seq, 3, 1)), 1)
+	})
+
+	t.Run("pairwise", func(t *testing.T) {
+		seq := xiter.Pairwise(xiter.FromSlice([]int{1, 2, 3}))
+		assert.Equal(t, []int{1, 2}, xiter.ToSliceSeq2Key(seq))
+		assert.Equal(t, []int{2, 3}, xiter.ToSliceSeq2Value(seq))
+		testLimit2(t, seq, 1)
+		assert.Len(t, xiter.ToSliceSeq2Key(xiter.Pairwise(xiter.FromSlice([]int{1}))), 0)
+
+		seq2 := xiter.Pairwise2(xiter.FromSliceIdx([]string{"a", "b"}))
+		assert.Equal(t, []union.U2[int, string]{{T1: 0, T2: "a"}}, xiter.ToSliceSeq2Key(seq2))
+		assert.Equal(t, []union.U2[int, string]{{T1: 1, T2: "b"}}, xiter.ToSliceSeq2Value(seq2))
+	})
+
+	t.Run("chunk by", func(t *testing.T) {
+		odd := func(v int) bool { return v%2 == 1 }
+		seq := xiter.ChunkBy(xiter.FromSlice([]int{1, 3, 2, 4, 5}), odd)
+		assert.Equal(t, []bool{true, false, true}, xiter.ToSliceSeq2Key(seq))
+		assert.Equal(t, [][]int{{1, 3}, {2, 4}, {5}}, xiter.ToSliceSeq2Value(seq))
+		testLimit2(t, seq, 1)
+		assert.Equal(t, [][]int{{1, 3}}, xiter.ToSliceSeq2Value(xiter.Limit2(seq, 1)))
+		assert.Len(t, xiter.ToSliceSeq2Key(xiter.ChunkBy(xiter.FromSlice([]int{}), odd)), 0)
+
+		// infinite sorted stream
+		div10 := xiter.ChunkBy(xiter.Range(0, 1<<62, 1), func(v int) int { return v / 10 })
+		assert.Equal(t, [][]int{_range(0, 10), _range(10, 20)}, xiter.ToSliceSeq2Value(xiter.Limit2(div10, 2)))
+	})
+
+	t.Run("group by", func(t *testing.T) {
+		odd := func(v int) bool { return v%2 == 1 }
+		seq := xiter.GroupBy(xiter.FromSlice([]int{2, 1, 3, 4, 5}), odd)
+		assert.Equal(t, []bool{false, true}, xiter.ToSliceSeq2Key(seq))
+		assert.Equal(t, [][]int{{2, 4}, {1, 3, 5}}, xiter.ToSliceSeq2Value(seq))
+		testLimit2(t, seq, 1)
+		assert.Len(t, xiter.ToSliceSeq2Key(xiter.GroupBy(xiter.FromSlice([]int{}), odd)), 0)
+	})
+
+	t.Run("scan", func(t *testing.T) {
+		mul := func(sum int, v int) int { return sum * v }
+		seq := xiter.Scan(mul, 1, xiter.FromSlice([]int{1, 2, 3, 4}))
+		assert.Equal(t, []int{1, 2, 6, 24}, xiter.ToSlice(seq))
+		assert.Equal(t, []int{1, 2, 6, 24}, xiter.ToSlice(seq))
+		testLimit(t, seq, 1)
+		assert.Len(t, xiter.ToSlice(xiter.Scan(mul, 1, xiter.FromSlice([]int{}))), 0)
+
+		join := xiter.Scan(func(s string, v int) string { return s + strconv.Itoa(v) }, ">", xiter.FromSlice([]int{1, 2}))
+		assert.Equal(t, []string{">1", ">12"}, xiter.ToSlice(join))
+
+		add := func(sum int, v int) int { return sum + v }
+		seq2 := xiter.ScanWithIndex(add, 0, xiter.FromSlice([]int{1, 2, 3}))
+		assert.Equal(t, []int{0, 1, 2}, xiter.ToSliceSeq2Key(seq2))
+		assert.Equal(t, []int{1, 3, 6}, xiter.ToSliceSeq2Value(seq2))
+		testLimit2(t, seq2, 1)
+	})
+
+	t.Run("running", func(t *testing.T) {
+		seq := xiter.FromSlice([]int{2, 1, 3, 0, 4})
+		assert.Equal(t, []int{2, 3, 6, 6, 10}, xiter.ToSlice(xiter.RunningSum(seq)))
+		assert.Equal(t, []int{2, 2, 3, 3, 4}, xiter.ToSlice(xiter.RunningMax(seq)))
+		assert.Equal(t, []int{2, 1, 1, 0, 0}, xiter.ToSlice(xiter.RunningMin(seq)))
+		assert.Equal(t, []float64{2, 1.5, 2, 1.5, 2}, xiter.ToSlice(xiter.RunningMean(seq)))
+		assert.Equal(t, []float64{0.5, 1}, xiter.ToSlice(xiter.RunningMean(xiter.FromSlice([]float64{0.5, 1.5}))))
+		assert.Len(t, xiter.ToSlice(xiter.RunningMean(xiter.FromSlice([]int{}))), 0)
+		// the sum does not overflow small integer types
+		assert.Equal(t, []float64{100, 100, 100}, xiter.ToSlice(xiter.RunningMean(xiter.FromSlice([]int8{100, 100, 100}))))
+		testLimit(t, xiter.RunningSum(seq), 1)
+		testLimit(t, xiter.RunningMax(seq), 1)
+		testLimit(t, xiter.RunningMin(seq), 1)
+		testLimit(t, xiter.RunningMean(seq), 1)
+
+		// running values of an infinite seq
+		assert.Equal(t, []int{0, 1, 3, 6}, xiter.ToSlice(xiter.Limit(xiter.RunningSum(xiter.Range(0, 1<<62, 1)), 4)))
+	})
 }
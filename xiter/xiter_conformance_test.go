@@ -0,0 +1,360 @@
+package xiter_test
+
+import (
+	"bufio"
+	"context"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dashjay/xiter/union"
+	"github.com/dashjay/xiter/xiter"
+	"github.com/dashjay/xiter/xitertest"
+)
+
+// TestXIterConformance checks every adapter against the iterator protocol with xitertest.
+func TestXIterConformance(t *testing.T) {
+	ints := func() xiter.Seq[int] { return xiter.FromSlice(_range(0, 10)) }
+	pairs := func() xiter.Seq2[int, int] { return xiter.FromSliceIdx(_range(0, 10)) }
+	even := func(v int) bool { return v%2 == 0 }
+	even2 := func(_, v int) bool { return v%2 == 0 }
+	lt5 := func(v int) bool { return v < 5 }
+	lt52 := func(_, v int) bool { return v < 5 }
+	cmp := func(a, b int) int { return a - b }
+	seeded := func() xiter.RandOption { return xiter.WithRand(rand.New(rand.NewSource(1))) }
+
+	t.Run("sources", func(t *testing.T) {
+		xitertest.TestSeq(t, ints())
+		xitertest.TestSeq(t, xiter.FromSlice([]int(nil)))
+		xitertest.TestSeq2(t, pairs())
+		xitertest.TestSeq(t, xiter.FromSliceReverse(_range(0, 10)))
+		xitertest.TestSeq(t, xiter.FromSliceShuffle(_range(0, 10)), xitertest.WithNondeterministic())
+		xitertest.TestSeqFunc(t, func() xiter.Seq[int] { return xiter.FromSliceShuffle(_range(0, 10), seeded()) })
+		m := map[int]int{1: 1, 2: 2, 3: 3}
+		xitertest.TestSeq(t, xiter.FromMapKeys(m), xitertest.WithNondeterministic())
+		xitertest.TestSeq(t, xiter.FromMapValues(m), xitertest.WithNondeterministic())
+		xitertest.TestSeq2(t, xiter.FromMapKeyAndValues(m), xitertest.WithNondeterministic())
+		xitertest.TestSeq(t, xiter.Range(0, 10, 3))
+		xitertest.TestSeq(t, xiter.Range(10, 0, -3))
+		xitertest.TestSeq(t, xiter.Generate(func() int { return 1 }), xitertest.WithMaxElements(20))
+		xitertest.TestSeq(t, xiter.GenerateCtx(context.Background(), func() int { return 1 }), xitertest.WithMaxElements(20))
+		xitertest.TestSeqFunc(t, func() xiter.Seq[int] {
+			ch := make(chan int, 10)
+			for i := 0; i < 10; i++ {
+				ch <- i
+			}
+			close(ch)
+			return xiter.FromChan(ch)
+		})
+		xitertest.TestSeqFunc(t, func() xiter.Seq[int] {
+			ch, stop := xiter.ToChanOpts(ints(), xiter.WithBuffer(2))
+			return stopAfter(xiter.FromChan(ch), stop)
+		})
+		xitertest.TestSeqFunc(t, func() xiter.Seq[int] {
+			ctx, cancel := context.WithCancel(context.Background())
+			return stopAfter(xiter.FromChanCtx(ctx, xiter.ToChanCtx(ctx, ints())), cancel)
+		})
+	})
+
+	t.Run("basic", func(t *testing.T) {
+		xitertest.TestSeq(t, xiter.Concat(ints(), xiter.FromSlice([]int(nil)), ints()))
+		xitertest.TestSeq(t, xiter.Concat(xiter.FromSlice([]int(nil)), ints()))
+		xitertest.TestSeq2(t, xiter.Concat2(pairs(), pairs()))
+		xitertest.TestSeq2(t, xiter.Concat2(xiter.FromSliceIdx([]int(nil)), pairs()))
+		xitertest.TestSeq(t, xiter.Filter(even, ints()))
+		xitertest.TestSeq2(t, xiter.Filter2(even2, pairs()))
+		xitertest.TestSeq(t, xiter.Limit(ints(), 5))
+		xitertest.TestSeq(t, xiter.Limit(ints(), 0))
+		xitertest.TestSeq2(t, xiter.Limit2(pairs(), 5))
+		xitertest.TestSeq(t, xiter.Skip(ints(), 5))
+		xitertest.TestSeq(t, xiter.Map(func(v int) int { return v * 2 }, ints()))
+		xitertest.TestSeq2(t, xiter.Map2(func(k, v int) (int, int) { return v, k }, pairs()))
+		xitertest.TestSeq2(t, xiter.MapToSeq2(ints(), func(v int) int { return v * 2 }))
+		xitertest.TestSeq2(t, xiter.MapToSeq2Value(ints(), func(v int) (int, int) { return v, v * 2 }))
+		xitertest.TestSeq(t, xiter.Seq2KeyToSeq(pairs()))
+		xitertest.TestSeq(t, xiter.Seq2ValueToSeq(pairs()))
+		xitertest.TestSeq(t, xiter.Seq2ToSeqUnion(pairs()))
+		xitertest.TestSeq(t, xiter.Zip(ints(), xiter.Limit(ints(), 5)))
+		xitertest.TestSeq(t, xiter.Zip2(pairs(), xiter.Limit2(pairs(), 5)))
+		xitertest.TestSeq(t, xiter.Merge(ints(), ints()))
+		xitertest.TestSeq2(t, xiter.Merge2(pairs(), pairs()))
+		xitertest.TestSeq(t, xiter.MergeFunc(ints(), ints(), cmp))
+		xitertest.TestSeq2(t, xiter.MergeFunc2(pairs(), pairs(), cmp))
+		xitertest.TestSeq(t, xiter.MergeN(ints(), ints(), ints()))
+		xitertest.TestSeq(t, xiter.MergeNFunc(cmp, ints(), ints(), ints()))
+		xitertest.TestSeq2(t, xiter.MergeN2(pairs(), pairs(), pairs()))
+		xitertest.TestSeq2(t, xiter.MergeNFunc2(cmp, pairs(), pairs(), pairs()))
+		xitertest.TestSeq(t, xiter.Compact(xiter.FromSlice([]int{1, 1, 2, 2, 3})))
+		xitertest.TestSeq(t, xiter.Reverse(ints()))
+		xitertest.TestSeq(t, xiter.Repeat(ints(), 3))
+		xitertest.TestSeq(t, xiter.RepeatCtx(context.Background(), ints(), 3))
+		xitertest.TestSeq(t, xiter.Cycle(ints()), xitertest.WithMaxElements(25))
+		xitertest.TestSeq(t, xiter.Replace(ints(), 2, 20, 1))
+		xitertest.TestSeq(t, xiter.ReplaceAll(ints(), 2, 20))
+		xitertest.TestSeq2(t, xiter.WithIndex(ints()))
+	})
+
+	t.Run("while and until", func(t *testing.T) {
+		xitertest.TestSeq(t, xiter.TakeWhile(lt5, ints()))
+		xitertest.TestSeq2(t, xiter.TakeWhile2(lt52, pairs()))
+		xitertest.TestSeq(t, xiter.TakeUntil(lt5, ints()))
+		xitertest.TestSeq2(t, xiter.TakeUntil2(lt52, pairs()))
+		xitertest.TestSeq(t, xiter.DropWhile(lt5, ints()))
+		xitertest.TestSeq2(t, xiter.DropWhile2(lt52, pairs()))
+		for _, half := range []int{0, 1} {
+			half := half
+			xitertest.TestSeqFunc(t, func() xiter.Seq[int] {
+				parts := make([]xiter.Seq[int], 2)
+				var stop func()
+				parts[0], parts[1], stop = xiter.Span(lt5, ints())
+				return stopAfter(parts[half], stop)
+			})
+			xitertest.TestSeqFunc(t, func() xiter.Seq[int] {
+				parts := make([]xiter.Seq[int], 2)
+				var stop func()
+				parts[0], parts[1], stop = xiter.SplitAt(ints(), 3)
+				return stopAfter(parts[half], stop)
+			})
+			xitertest.TestSeq2Func(t, func() xiter.Seq2[int, int] {
+				parts := make([]xiter.Seq2[int, int], 2)
+				var stop func()
+				parts[0], parts[1], stop = xiter.Span2(lt52, pairs())
+				return stopAfter2(parts[half], stop)
+			})
+			xitertest.TestSeq2Func(t, func() xiter.Seq2[int, int] {
+				parts := make([]xiter.Seq2[int, int], 2)
+				var stop func()
+				parts[0], parts[1], stop = xiter.SplitAt2(pairs(), 3)
+				return stopAfter2(parts[half], stop)
+			})
+		}
+	})
+
+	t.Run("grouping", func(t *testing.T) {
+		xitertest.TestSeq(t, xiter.Chunk(ints(), 3))
+		xitertest.TestSeq(t, xiter.Chunk(ints(), 5))
+		xitertest.TestSeq2(t, xiter.ChunkBy(ints(), lt5))
+		xitertest.TestSeq2(t, xiter.GroupBy(ints(), even))
+		xitertest.TestSeq(t, xiter.Window(ints(), 3, 2))
+		xitertest.TestSeq(t, xiter.Window2(pairs(), 3, 2))
+		xitertest.TestSeq(t, xiter.Map(cloneInts, xiter.WindowInPlace(ints(), 3, 2)))
+		xitertest.TestSeq(t, xiter.Map(clonePairs, xiter.WindowInPlace2(pairs(), 3, 2)))
+		xitertest.TestSeq2(t, xiter.Pairwise(ints()))
+		xitertest.TestSeq2(t, xiter.Pairwise2(pairs()))
+		xitertest.TestSeq(t, xiter.Scan(func(sum, v int) int { return sum + v }, 0, ints()))
+		xitertest.TestSeq2(t, xiter.ScanWithIndex(func(sum, v int) int { return sum + v }, 0, ints()))
+		xitertest.TestSeq(t, xiter.RunningSum(ints()))
+		xitertest.TestSeq(t, xiter.RunningMean(ints()))
+		xitertest.TestSeq(t, xiter.RunningMin(xiter.Reverse(ints())))
+		xitertest.TestSeq(t, xiter.RunningMax(ints()))
+		xitertest.TestSeq(t, xiter.BatchByTimeOrSize(ints(), 3, time.Hour))
+	})
+
+	t.Run("sets", func(t *testing.T) {
+		onlyLeft, onlyRight := xiter.Difference(ints(), xiter.Range(5, 15, 1))
+		xitertest.TestSeq(t, onlyLeft, xitertest.WithNondeterministic())
+		xitertest.TestSeq(t, onlyRight, xitertest.WithNondeterministic())
+		xitertest.TestSeq(t, xiter.Intersect(ints(), xiter.Range(5, 15, 1)), xitertest.WithNondeterministic())
+		xitertest.TestSeq(t, xiter.Union(ints(), xiter.Range(5, 15, 1)), xitertest.WithNondeterministic())
+		xitertest.TestSeq(t, xiter.Uniq(xiter.Concat(ints(), ints())))
+		xitertest.TestSeq(t, xiter.UniqBy(ints(), even))
+		xitertest.TestSeq(t, xiter.UniqConsecutive(xiter.FromSlice([]int{1, 1, 2, 1})))
+		xitertest.TestSeq(t, xiter.UniqConsecutiveBy(xiter.FromSlice([]int{1, 3, 2, 4, 5}), even))
+		xitertest.TestSeq(t, xiter.UniqWindow(xiter.Concat(ints(), ints()), 3))
+		xitertest.TestSeq(t, xiter.UniqWindowBy(xiter.Concat(ints(), ints()), 3, even))
+		xitertest.TestSeq(t, xiter.UniqBloom(xiter.Concat(ints(), ints()), 100, 0.001, strconv.Itoa))
+		xitertest.TestSeq(t, xiter.SortedUnion(ints(), xiter.Range(5, 15, 1)))
+		xitertest.TestSeq(t, xiter.SortedUnionFunc(ints(), xiter.Range(5, 15, 1), cmp))
+		xitertest.TestSeq(t, xiter.SortedIntersect(ints(), xiter.Range(5, 15, 1)))
+		xitertest.TestSeq(t, xiter.SortedIntersectFunc(ints(), xiter.Range(5, 15, 1), cmp))
+		xitertest.TestSeq(t, xiter.SortedDifference(ints(), xiter.Range(5, 15, 1)))
+		xitertest.TestSeq(t, xiter.SortedDifferenceFunc(ints(), xiter.Range(5, 15, 1), cmp))
+		xitertest.TestSeq(t, xiter.SortedSymmetricDifference(ints(), xiter.Range(5, 15, 1)))
+		xitertest.TestSeq(t, xiter.SortedSymmetricDifferenceFunc(ints(), xiter.Range(5, 15, 1), cmp))
+		right := xiter.MapToSeq2(xiter.Range(5, 15, 1), func(v int) int { return v })
+		xitertest.TestSeq2(t, xiter.SortedUnion2(pairs(), right))
+		xitertest.TestSeq2(t, xiter.SortedUnionFunc2(pairs(), right, cmp))
+		xitertest.TestSeq2(t, xiter.SortedIntersect2(pairs(), right))
+		xitertest.TestSeq2(t, xiter.SortedIntersectFunc2(pairs(), right, cmp))
+		xitertest.TestSeq2(t, xiter.SortedDifference2(pairs(), right))
+		xitertest.TestSeq2(t, xiter.SortedDifferenceFunc2(pairs(), right, cmp))
+		xitertest.TestSeq2(t, xiter.SortedSymmetricDifference2(pairs(), right))
+		xitertest.TestSeq2(t, xiter.SortedSymmetricDifferenceFunc2(pairs(), right, cmp))
+	})
+
+	t.Run("joins", func(t *testing.T) {
+		id := func(v int) int { return v }
+		half := func(v int) int { return v / 2 }
+		xitertest.TestSeq2(t, xiter.HashJoin(ints(), xiter.Range(5, 15, 1), id, id))
+		xitertest.TestSeq2(t, xiter.LeftJoin(ints(), xiter.Range(5, 15, 1), id, id))
+		xitertest.TestSeq2(t, xiter.FullOuterJoin(ints(), xiter.Range(5, 15, 1), id, id))
+		xitertest.TestSeq2(t, xiter.SortMergeJoin(ints(), ints(), id, half))
+		xitertest.TestSeq2(t, xiter.SortMergeJoinFunc(ints(), ints(), id, half, cmp))
+		right := xiter.MapToSeq2(xiter.Range(5, 15, 1), id)
+		xitertest.TestSeq2(t, xiter.HashJoin2(pairs(), right))
+		xitertest.TestSeq2(t, xiter.LeftJoin2(pairs(), right))
+		xitertest.TestSeq2(t, xiter.FullOuterJoin2(pairs(), right))
+		xitertest.TestSeq2(t, xiter.SortMergeJoin2(pairs(), right))
+		xitertest.TestSeq2(t, xiter.SortMergeJoinFunc2(pairs(), right, cmp))
+	})
+
+	t.Run("sort", func(t *testing.T) {
+		xitertest.TestSeq(t, xiter.Sort(xiter.Reverse(ints())))
+		xitertest.TestSeq(t, xiter.SortFunc(xiter.Reverse(ints()), cmp))
+		xitertest.TestSeq2(t, xiter.SeqErrToSeq2(xiter.ExternalSort(xiter.Reverse(ints()),
+			xiter.WithMaxInMemory(3), xiter.WithTempDir(t.TempDir()))))
+		xitertest.TestSeq2(t, xiter.SeqErrToSeq2(xiter.ExternalSortFunc(xiter.Reverse(ints()), cmp,
+			xiter.WithMaxInMemory(3), xiter.WithTempDir(t.TempDir()))))
+	})
+
+	t.Run("combinatorics", func(t *testing.T) {
+		in := []int{1, 2, 3, 4}
+		xitertest.TestSeq(t, xiter.Combinations(in, 2))
+		xitertest.TestSeq(t, xiter.Map(cloneInts, xiter.CombinationsInPlace(in, 2)))
+		xitertest.TestSeq(t, xiter.CombinationsWithReplacement(in, 2))
+		xitertest.TestSeq(t, xiter.Map(cloneInts, xiter.CombinationsWithReplacementInPlace(in, 2)))
+		xitertest.TestSeq(t, xiter.Permutations(in, 2))
+		xitertest.TestSeq(t, xiter.Map(cloneInts, xiter.PermutationsInPlace(in, 2)))
+		xitertest.TestSeq(t, xiter.PowerSet(in))
+		xitertest.TestSeq(t, xiter.Map(cloneInts, xiter.PowerSetInPlace(in)))
+		xitertest.TestSeq(t, xiter.Product(in, in))
+		xitertest.TestSeq(t, xiter.Map(cloneInts, xiter.ProductInPlace(in, in)))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		seqErr := xiter.SeqToSeqErr(ints())
+		xitertest.TestSeq2(t, xiter.SeqErrToSeq2(seqErr))
+		xitertest.TestSeq2(t, xiter.SeqErrToSeq2(xiter.Seq2ToSeqErr(xiter.SeqErrToSeq2(seqErr))))
+		xitertest.TestSeq2(t, xiter.SeqErrToSeq2(xiter.MapErr(func(v int) (int, error) { return v * 2, nil }, seqErr)))
+		xitertest.TestSeq2(t, xiter.SeqErrToSeq2(xiter.FilterErr(func(v int) (bool, error) { return even(v), nil }, seqErr)))
+		xitertest.TestSeq2Func(t, func() xiter.Seq2[string, error] {
+			return xiter.SeqErrToSeq2(xiter.Lines(strings.NewReader("a\nb\nc\n")))
+		})
+		xitertest.TestSeq2Func(t, func() xiter.Seq2[string, error] {
+			return xiter.SeqErrToSeq2(xiter.ScanReader(strings.NewReader("a b c"), bufio.ScanWords))
+		})
+		xitertest.TestSeq2Func(t, func() xiter.Seq2[[]byte, error] {
+			return xiter.SeqErrToSeq2(xiter.ReadRecords(strings.NewReader("abcdefg"), 2))
+		})
+		dir := t.TempDir()
+		for _, name := range []string{"a", "b", "c"} {
+			assert.Nil(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+		}
+		name := func(e fs.DirEntry) (string, error) { return e.Name(), nil }
+		xitertest.TestSeq2(t, xiter.SeqErrToSeq2(xiter.MapErr(name, xiter.DirEntries(dir))))
+		path := func(e xiter.WalkEntry) (string, error) { return e.Path, nil }
+		xitertest.TestSeq2(t, xiter.SeqErrToSeq2(xiter.MapErr(path, xiter.WalkDir(dir))))
+		xitertest.TestSeq2(t, xiter.SeqErrToSeq2(xiter.MapErr(path, xiter.WalkDirFS(os.DirFS(dir), "."))))
+	})
+
+	t.Run("context", func(t *testing.T) {
+		ctx := context.Background()
+		xitertest.TestSeq(t, xiter.WithContext(ctx, ints()))
+		xitertest.TestSeq2(t, xiter.WithContext2(ctx, pairs()))
+		seq, _ := xiter.WithContextErr(ctx, ints())
+		xitertest.TestSeq(t, seq)
+		seq2, _ := xiter.WithContextErr2(ctx, pairs())
+		xitertest.TestSeq2(t, seq2)
+	})
+
+	t.Run("concurrency", func(t *testing.T) {
+		double := func(v int) int { return v * 2 }
+		xitertest.TestSeq(t, xiter.ParallelMap(double, ints(), xiter.WithPoolSize(3)))
+		xitertest.TestSeq(t, xiter.ParallelMap(double, ints(), xiter.WithPoolSize(3), xiter.WithOrdered(false)),
+			xitertest.WithNondeterministic())
+		xitertest.TestSeq(t, xiter.ParallelFilter(even, ints(), xiter.WithPoolSize(3)))
+		xitertest.TestSeq(t, xiter.FanIn(ints(), ints()), xitertest.WithNondeterministic())
+		xitertest.TestSeqFunc(t, func() xiter.Seq[int] {
+			p := xiter.NewPeekable(ints())
+			return stopAfter(p.Seq(), p.Stop)
+		})
+		xitertest.TestSeq2Func(t, func() xiter.Seq2[int, int] {
+			p := xiter.NewPeekable2(pairs())
+			return stopAfter2(p.Seq2(), p.Stop)
+		})
+		xitertest.TestSeqFunc(t, func() xiter.Seq[int] {
+			seqs, stop := xiter.Broadcast(ints(), 1, 2, xiter.BroadcastBlock)
+			return stopAfter(seqs[0], stop)
+		})
+		xitertest.TestSeqFunc(t, func() xiter.Seq[int] {
+			seqs, stop := xiter.Tee(ints(), 2, 20)
+			return stopAfter(seqs[1], stop)
+		})
+		memo, stop := xiter.Memoize(ints())
+		defer stop()
+		xitertest.TestSeq(t, memo)
+		bounded, stop := xiter.MemoizeBounded(ints(), 3, xiter.SpillRerun)
+		defer stop()
+		xitertest.TestSeq(t, bounded)
+	})
+
+	t.Run("time", func(t *testing.T) {
+		xitertest.TestSeq(t, xiter.Throttle(ints(), time.Microsecond))
+		xitertest.TestSeq(t, xiter.Debounce(ints(), time.Hour))
+		xitertest.TestSeq(t, xiter.SampleEvery(ints(), time.Hour))
+	})
+
+	t.Run("random", func(t *testing.T) {
+		xitertest.TestSeq(t, xiter.SampleBernoulli(ints(), 0.5), xitertest.WithNondeterministic())
+		xitertest.TestSeqFunc(t, func() xiter.Seq[int] { return xiter.SampleBernoulli(ints(), 0.5, seeded()) })
+	})
+
+	t.Run("reuse", func(t *testing.T) {
+		// Limit, Skip and Replace used to count down n captured outside the returned seq,
+		// so the second iteration yielded nothing or everything.
+		limited := xiter.Limit(ints(), 3)
+		assert.Equal(t, []int{0, 1, 2}, xiter.ToSlice(limited))
+		assert.Equal(t, []int{0, 1, 2}, xiter.ToSlice(limited))
+		limited2 := xiter.Limit2(pairs(), 3)
+		assert.Equal(t, []int{0, 1, 2}, xiter.ToSliceSeq2Key(limited2))
+		assert.Equal(t, []int{0, 1, 2}, xiter.ToSliceSeq2Key(limited2))
+		skipped := xiter.Skip(ints(), 7)
+		assert.Equal(t, []int{7, 8, 9}, xiter.ToSlice(skipped))
+		assert.Equal(t, []int{7, 8, 9}, xiter.ToSlice(skipped))
+
+		replaced := xiter.Replace(ints(), 2, 20, 1)
+		assert.Equal(t, xiter.ToSlice(replaced), xiter.ToSlice(replaced))
+
+		// Concat and Concat2 used to stop at an empty seq.
+		empty := xiter.FromSlice([]int(nil))
+		assert.Equal(t, _range(0, 10), xiter.ToSlice(xiter.Concat(empty, ints())))
+		concat2 := xiter.Concat2(xiter.FromSliceIdx([]int(nil)), pairs())
+		assert.Equal(t, []int{0}, xiter.ToSliceSeq2Key(xiter.Limit2(concat2, 1)))
+		assert.Equal(t, _range(0, 10), xiter.ToSliceSeq2Value(concat2))
+
+		// Chunk used to yield an empty trailing chunk, even after the consumer stopped.
+		assert.Equal(t, [][]int{_range(0, 5), _range(5, 10)}, xiter.ToSlice(xiter.Chunk(ints(), 5)))
+		assert.Equal(t, [][]int{_range(0, 5)}, xiter.ToSlice(xiter.Limit(xiter.Chunk(ints(), 5), 1)))
+		assert.Len(t, xiter.ToSlice(xiter.Chunk(empty, 5)), 0)
+	})
+}
+
+// stopAfter returns a seq which calls stop after iterating seq.
+func stopAfter[T any](seq xiter.Seq[T], stop func()) xiter.Seq[T] {
+	return func(yield func(T) bool) {
+		defer stop()
+		seq(yield)
+	}
+}
+
+func stopAfter2[K, V any](seq xiter.Seq2[K, V], stop func()) xiter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		defer stop()
+		seq(yield)
+	}
+}
+
+func cloneInts(in []int) []int {
+	return append([]int(nil), in...)
+}
+
+func clonePairs(in []union.U2[int, int]) []union.U2[int, int] {
+	return append([]union.U2[int, int](nil), in...)
+}
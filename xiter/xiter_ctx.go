@@ -0,0 +1,189 @@
+package xiter
+
+import (
+	"context"
+)
+
+// WithContext returns a Seq that yields the elements of seq until ctx is done.
+// The context is checked before every element is yielded, so a cancelled ctx stops
+// even infinite sequences like Generate or Cycle.
+//
+// WithContext can not interrupt a source which is blocked inside itself (e.g. FromChan waiting on an empty channel),
+// use FromChanCtx for these cases.
+//
+// EXAMPLE:
+//
+//	ctx, cancel := context.WithCancel(context.Background())
+//	seq := xiter.WithContext(ctx, xiter.Generate(func() int { return 1 }))
+//	seq(func(v int) bool {
+//		cancel() // iteration stops after this element
+//		return true
+//	})
+func WithContext[T any](ctx context.Context, seq Seq[T]) Seq[T] {
+	return func(yield func(T) bool) {
+		if ctxDone(ctx) {
+			return
+		}
+		seq(func(v T) bool {
+			if ctxDone(ctx) {
+				return false
+			}
+			return yield(v)
+		})
+	}
+}
+
+// WithContext2 returns a Seq2 that yields the key-value pairs of seq until ctx is done.
+// Like WithContext but run with Seq2
+func WithContext2[K, V any](ctx context.Context, seq Seq2[K, V]) Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if ctxDone(ctx) {
+			return
+		}
+		seq(func(k K, v V) bool {
+			if ctxDone(ctx) {
+				return false
+			}
+			return yield(k, v)
+		})
+	}
+}
+
+// WithContextErr is like WithContext, and additionally returns a function reporting why the last iteration ended.
+// The function returns nil if seq was exhausted or the consumer stopped by itself,
+// otherwise it returns the cancellation cause of ctx (context.Cause on go1.20+, ctx.Err() before).
+//
+// EXAMPLE:
+//
+//	seq, errFn := xiter.WithContextErr(ctx, xiter.FromSlice([]int{1, 2, 3}))
+//	values := xiter.ToSlice(seq)
+//	if err := errFn(); err != nil {
+//		// values is incomplete, ctx was cancelled because of err
+//	}
+func WithContextErr[T any](ctx context.Context, seq Seq[T]) (Seq[T], func() error) {
+	var err error
+	return func(yield func(T) bool) {
+			err = nil
+			if ctxDone(ctx) {
+				err = contextCause(ctx)
+				return
+			}
+			seq(func(v T) bool {
+				if ctxDone(ctx) {
+					err = contextCause(ctx)
+					return false
+				}
+				return yield(v)
+			})
+		}, func() error {
+			return err
+		}
+}
+
+// WithContextErr2 is like WithContextErr but run with Seq2
+func WithContextErr2[K, V any](ctx context.Context, seq Seq2[K, V]) (Seq2[K, V], func() error) {
+	var err error
+	return func(yield func(K, V) bool) {
+			err = nil
+			if ctxDone(ctx) {
+				err = contextCause(ctx)
+				return
+			}
+			seq(func(k K, v V) bool {
+				if ctxDone(ctx) {
+					err = contextCause(ctx)
+					return false
+				}
+				return yield(k, v)
+			})
+		}, func() error {
+			return err
+		}
+}
+
+// FromChanCtx creates a Seq from a Go channel like FromChan.
+// It yields elements from the channel until the channel is closed, the consumer stops iterating or ctx is done,
+// and it never stays blocked on an empty channel after ctx is done.
+//
+// EXAMPLE:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+//	defer cancel()
+//	ch := make(chan int) // never closed
+//	_ = xiter.ToSlice(xiter.FromChanCtx(ctx, ch)) // returns after 1 second
+func FromChanCtx[T any](ctx context.Context, in <-chan T) Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case elem, ok := <-in:
+				if !ok || ctxDone(ctx) || !yield(elem) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// GenerateCtx returns a Seq where each element is produced by calling fn until ctx is done.
+// Like Generate but stops when ctx is done.
+//
+// EXAMPLE:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+//	defer cancel()
+//	seq := xiter.GenerateCtx(ctx, func() int { return rand.Intn(100) })
+//	// seq yields random numbers for 1 second
+func GenerateCtx[T any](ctx context.Context, fn func() T) Seq[T] {
+	return func(yield func(T) bool) {
+		for !ctxDone(ctx) && yield(fn()) {
+		}
+	}
+}
+
+// RepeatCtx return a seq that repeat seq for count times until ctx is done.
+// Like Repeat but stops when ctx is done.
+func RepeatCtx[T any](ctx context.Context, seq Seq[T], count int) Seq[T] {
+	return WithContext(ctx, Repeat(seq, count))
+}
+
+// ToChanCtx sends all elements of seq to a returned channel and closes it when the seq is exhausted or ctx is done.
+// Unlike ToChan, the sending goroutine exits once ctx is done even if nobody reads from the channel anymore.
+//
+// EXAMPLE:
+//
+//	ctx, cancel := context.WithCancel(context.Background())
+//	ch := xiter.ToChanCtx(ctx, xiter.Range(0, 100, 1))
+//	fmt.Println(<-ch)
+//	cancel() // the goroutine sending to ch exits
+//	// output:
+//	// 0
+func ToChanCtx[T any](ctx context.Context, seq Seq[T]) <-chan T {
+	ch := make(chan T)
+	go func() {
+		defer close(ch)
+		if ctxDone(ctx) {
+			return
+		}
+		seq(func(v T) bool {
+			select {
+			case ch <- v:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	return ch
+}
+
+// ctxDone reports whether ctx is done without blocking.
+func ctxDone(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,11 @@
+//go:build go1.20
+// +build go1.20
+
+package xiter
+
+import "context"
+
+// contextCause returns the cause of ctx, only available in go1.20.
+func contextCause(ctx context.Context) error {
+	return context.Cause(ctx)
+}
@@ -0,0 +1,145 @@
+package xiter_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/dashjay/xiter/xiter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXIterCtx(t *testing.T) {
+	t.Run("with context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		seq := xiter.WithContext(ctx, xiter.FromSlice(_range(0, 10)))
+		assert.Equal(t, _range(0, 10), xiter.ToSlice(seq))
+		testLimit(t, seq, 1)
+
+		var res []int
+		xiter.WithContext(ctx, xiter.Cycle(xiter.FromSlice(_range(0, 3))))(func(v int) bool {
+			res = append(res, v)
+			if len(res) == 5 {
+				cancel()
+			}
+			return true
+		})
+		assert.Equal(t, []int{0, 1, 2, 0, 1}, res)
+		assert.Len(t, xiter.ToSlice(seq), 0)
+	})
+
+	t.Run("with context2", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		seq := xiter.WithContext2(ctx, xiter.FromSliceIdx(_range(0, 10)))
+		assert.Equal(t, _range(0, 10), xiter.ToSliceSeq2Value(seq))
+		testLimit2(t, seq, 1)
+
+		var keys []int
+		seq(func(k int, v int) bool {
+			keys = append(keys, k)
+			if k == 2 {
+				cancel()
+			}
+			return true
+		})
+		assert.Equal(t, []int{0, 1, 2}, keys)
+		assert.Len(t, xiter.ToSliceSeq2Key(seq), 0)
+	})
+
+	t.Run("with context err", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		seq, errFn := xiter.WithContextErr(ctx, xiter.Generate(func() int { return 1 }))
+		assert.Equal(t, []int{1, 1, 1}, xiter.ToSlice(xiter.Limit(seq, 3)))
+		assert.Nil(t, errFn())
+
+		n := 0
+		seq(func(int) bool {
+			n++
+			if n == 3 {
+				cancel()
+			}
+			return true
+		})
+		assert.Equal(t, 3, n)
+		assert.True(t, errors.Is(errFn(), context.Canceled))
+
+		exhausted, errFn := xiter.WithContextErr(context.Background(), xiter.FromSlice(_range(0, 3)))
+		assert.Equal(t, _range(0, 3), xiter.ToSlice(exhausted))
+		assert.Nil(t, errFn())
+	})
+
+	t.Run("with context err2", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		seq, errFn := xiter.WithContextErr2(ctx, xiter.FromSliceIdx(_range(0, 3)))
+		assert.Len(t, xiter.ToSliceSeq2Key(seq), 0)
+		assert.True(t, errors.Is(errFn(), context.Canceled))
+
+		seq, errFn = xiter.WithContextErr2(context.Background(), xiter.FromSliceIdx(_range(0, 3)))
+		assert.Equal(t, _range(0, 3), xiter.ToSliceSeq2Value(seq))
+		assert.Nil(t, errFn())
+	})
+
+	t.Run("from chan ctx", func(t *testing.T) {
+		ch := make(chan int, 10)
+		for i := 0; i < 10; i++ {
+			ch <- i
+		}
+		close(ch)
+		assert.Equal(t, _range(0, 10), xiter.ToSlice(xiter.FromChanCtx(context.Background(), ch)))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		blocked := make(chan int)
+		assert.Len(t, xiter.ToSlice(xiter.FromChanCtx(ctx, blocked)), 0)
+
+		ch = make(chan int, 10)
+		for i := 0; i < 10; i++ {
+			ch <- i
+		}
+		testLimit(t, xiter.FromChanCtx(context.Background(), ch), 1)
+	})
+
+	t.Run("generate ctx", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		i := 0
+		gen := xiter.GenerateCtx(ctx, func() int {
+			i++
+			if i == 5 {
+				cancel()
+			}
+			return i
+		})
+		assert.Equal(t, []int{1, 2}, xiter.ToSlice(xiter.Limit(gen, 2)))
+		assert.Equal(t, []int{3, 4, 5}, xiter.ToSlice(gen))
+	})
+
+	t.Run("repeat ctx", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		seq := xiter.RepeatCtx(ctx, xiter.FromSlice([]int{1, 2}), 3)
+		assert.Equal(t, []int{1, 2, 1, 2, 1, 2}, xiter.ToSlice(seq))
+		cancel()
+		assert.Len(t, xiter.ToSlice(seq), 0)
+	})
+
+	t.Run("to chan ctx", func(t *testing.T) {
+		var result []int
+		for v := range xiter.ToChanCtx(context.Background(), xiter.FromSlice(_range(0, 10))) {
+			result = append(result, v)
+		}
+		assert.Equal(t, _range(0, 10), result)
+
+		ng := stableNumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := xiter.ToChanCtx(ctx, xiter.Generate(func() int { return 1 }))
+		assert.Equal(t, 1, <-ch)
+		cancel()
+		for range ch {
+		}
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, ng, runtime.NumGoroutine())
+	})
+}
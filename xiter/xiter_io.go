@@ -0,0 +1,211 @@
+package xiter
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ReaderOption configures the buffers of the sources reading from io.Reader, such as Lines, ScanReader and ReadRecords.
+type ReaderOption func(*readerOptions)
+
+type readerOptions struct {
+	bufSize      int
+	maxTokenSize int
+}
+
+// WithReadBufferSize sets the initial size of the read buffer, default is 4096. Non-positive n is ignored.
+func WithReadBufferSize(n int) ReaderOption {
+	return func(o *readerOptions) {
+		if n > 0 {
+			o.bufSize = n
+		}
+	}
+}
+
+// WithMaxTokenSize sets the max size of a line or a token for Lines and ScanReader, default is bufio.MaxScanTokenSize,
+// which is 64KB. A longer token makes them yield bufio.ErrTooLong. Non-positive n is ignored.
+func WithMaxTokenSize(n int) ReaderOption {
+	return func(o *readerOptions) {
+		if n > 0 {
+			o.maxTokenSize = n
+		}
+	}
+}
+
+func newReaderOptions(opts []ReaderOption) *readerOptions {
+	o := &readerOptions{bufSize: 4096, maxTokenSize: bufio.MaxScanTokenSize}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.bufSize > o.maxTokenSize {
+		o.bufSize = o.maxTokenSize
+	}
+	return o
+}
+
+// Lines returns a SeqErr over the lines of r, the line endings "\n" or "\r\n" are removed,
+// and the last line is yielded even if it has no line ending.
+// It yields the error of r or bufio.ErrTooLong for a line longer than the size set by WithMaxTokenSize, and stops then.
+//
+// r is consumed by the iteration, so the SeqErr should be iterated only once.
+//
+// EXAMPLE:
+//
+//	lines, err := xiter.TryToSlice(xiter.Lines(strings.NewReader("a\nb\r\nc")))
+//	lines 👉 [a b c]
+//	err 👉 nil
+func Lines(r io.Reader, opts ...ReaderOption) SeqErr[string] {
+	return ScanReader(r, bufio.ScanLines, opts...)
+}
+
+// ScanReader returns a SeqErr over the tokens of r split by split, like bufio.Scanner,
+// such as bufio.ScanWords, bufio.ScanRunes or a custom bufio.SplitFunc.
+// It yields the error of r or split, or bufio.ErrTooLong for a token longer than the size set by WithMaxTokenSize,
+// and stops then.
+//
+// r is consumed by the iteration, so the SeqErr should be iterated only once.
+//
+// EXAMPLE:
+//
+//	words, _ := xiter.TryToSlice(xiter.ScanReader(strings.NewReader("hello  xiter\nworld"), bufio.ScanWords))
+//	words 👉 [hello xiter world]
+func ScanReader(r io.Reader, split bufio.SplitFunc, opts ...ReaderOption) SeqErr[string] {
+	o := newReaderOptions(opts)
+	return func(yield func(string, error) bool) {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, o.bufSize), o.maxTokenSize)
+		scanner.Split(split)
+		for scanner.Scan() {
+			if !yield(scanner.Text(), nil) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield("", err)
+		}
+	}
+}
+
+// ReadRecords returns a SeqErr over the fixed-size records of r, every record is a new slice of size bytes.
+// If r ends in the middle of a record, it yields io.ErrUnexpectedEOF, and it yields other errors of r as they are,
+// it stops after an error. It panics if size is not positive.
+//
+// r is consumed by the iteration, so the SeqErr should be iterated only once.
+//
+// EXAMPLE:
+//
+//	records, err := xiter.TryToSlice(xiter.ReadRecords(bytes.NewReader([]byte("aabbc")), 2))
+//	records 👉 [[97 97] [98 98]]
+//	err 👉 io.ErrUnexpectedEOF
+func ReadRecords(r io.Reader, size int, opts ...ReaderOption) SeqErr[[]byte] {
+	mustBePositive("size", size)
+	o := newReaderOptions(opts)
+	return func(yield func([]byte, error) bool) {
+		br := bufio.NewReaderSize(r, o.bufSize)
+		for {
+			record := make([]byte, size)
+			if _, err := io.ReadFull(br, record); err != nil {
+				if !errors.Is(err, io.EOF) {
+					yield(nil, err)
+				}
+				return
+			}
+			if !yield(record, nil) {
+				return
+			}
+		}
+	}
+}
+
+// dirBatchSize is the number of entries DirEntries reads from the directory at a time.
+const dirBatchSize = 128
+
+// DirEntries returns a SeqErr over the entries of the directory dir, in directory order rather than sorted like
+// os.ReadDir, and the entries are read in batches, so a huge directory is not loaded at once.
+// It yields the error of opening or reading dir, and stops then.
+//
+// EXAMPLE:
+//
+//	names, err := xiter.TryToSlice(xiter.MapErr(func(e fs.DirEntry) (string, error) {
+//		return e.Name(), nil
+//	}, xiter.DirEntries(".")))
+func DirEntries(dir string) SeqErr[fs.DirEntry] {
+	return func(yield func(fs.DirEntry, error) bool) {
+		f, err := os.Open(filepath.Clean(dir))
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer f.Close()
+		for {
+			entries, err := f.ReadDir(dirBatchSize)
+			for _, entry := range entries {
+				if !yield(entry, nil) {
+					return
+				}
+			}
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					yield(nil, err)
+				}
+				return
+			}
+		}
+	}
+}
+
+// WalkEntry is a file or directory visited by WalkDir, Path is the path of it joined with the root.
+type WalkEntry struct {
+	Path string
+	fs.DirEntry
+}
+
+// stopWalk is returned to abort the walk when the consumer stops iterating.
+type stopWalk struct{}
+
+func (stopWalk) Error() string { return "xiter: stop walk" }
+
+// WalkDir returns a SeqErr over the file tree rooted at root in lexical order, including root, like filepath.WalkDir.
+// An error visiting a file or reading a directory is yielded, and the walk goes on if the consumer keeps iterating,
+// so both TryToSlice and CollectErrors work with it. The walk stops as soon as the consumer stops iterating.
+//
+// EXAMPLE:
+//
+//	goFiles, err := xiter.TryToSlice(xiter.FilterErr(func(e xiter.WalkEntry) (bool, error) {
+//		return !e.IsDir() && filepath.Ext(e.Path) == ".go", nil
+//	}, xiter.WalkDir(".")))
+func WalkDir(root string) SeqErr[WalkEntry] {
+	return func(yield func(WalkEntry, error) bool) {
+		_ = filepath.WalkDir(root, walkDirFunc(yield))
+	}
+}
+
+// WalkDirFS is like WalkDir but walks the file tree of fsys, like fs.WalkDir.
+//
+// EXAMPLE:
+//
+//	entries, err := xiter.TryToSlice(xiter.WalkDirFS(os.DirFS("/etc"), "."))
+func WalkDirFS(fsys fs.FS, root string) SeqErr[WalkEntry] {
+	return func(yield func(WalkEntry, error) bool) {
+		_ = fs.WalkDir(fsys, root, walkDirFunc(yield))
+	}
+}
+
+func walkDirFunc(yield func(WalkEntry, error) bool) fs.WalkDirFunc {
+	return func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if !yield(WalkEntry{}, err) {
+				return stopWalk{}
+			}
+			return nil
+		}
+		if !yield(WalkEntry{Path: path, DirEntry: d}, nil) {
+			return stopWalk{}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,184 @@
+package xiter_test
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dashjay/xiter/xiter"
+)
+
+// failReader returns the data and then err.
+type failReader struct {
+	data []byte
+	err  error
+}
+
+func (r *failReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestXIterIO(t *testing.T) {
+	errRead := errors.New("read failed")
+
+	t.Run("lines", func(t *testing.T) {
+		lines, err := xiter.TryToSlice(xiter.Lines(strings.NewReader("a\nb\r\n\nc")))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a", "b", "", "c"}, lines)
+
+		lines, err = xiter.TryToSlice(xiter.Lines(strings.NewReader("")))
+		assert.Nil(t, err)
+		assert.Len(t, lines, 0)
+
+		lines, err = xiter.TryToSlice(xiter.Lines(&failReader{data: []byte("a\nb\nc"), err: errRead}))
+		assert.Equal(t, errRead, err)
+		assert.Equal(t, []string{"a", "b", "c"}, lines)
+
+		assert.Equal(t, []string{"0", "1"}, xiter.ToSlice(xiter.Limit(
+			xiter.Seq2KeyToSeq(xiter.SeqErrToSeq2(xiter.Lines(strings.NewReader("0\n1\n2\n")))), 2)))
+	})
+
+	t.Run("long lines", func(t *testing.T) {
+		long := strings.Repeat("x", 100)
+		input := "a\n" + long + "\nb"
+		lines, err := xiter.TryToSlice(xiter.Lines(strings.NewReader(input), xiter.WithMaxTokenSize(50)))
+		assert.ErrorIs(t, err, bufio.ErrTooLong)
+		assert.Equal(t, []string{"a"}, lines)
+
+		lines, err = xiter.TryToSlice(xiter.Lines(strings.NewReader(input),
+			xiter.WithReadBufferSize(16), xiter.WithMaxTokenSize(200)))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a", long, "b"}, lines)
+
+		long = strings.Repeat("x", 1<<20)
+		lines, err = xiter.TryToSlice(xiter.Lines(strings.NewReader(long), xiter.WithMaxTokenSize(2<<20)))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{long}, lines)
+	})
+
+	t.Run("scan reader", func(t *testing.T) {
+		words, err := xiter.TryToSlice(xiter.ScanReader(strings.NewReader("hello  xiter\nworld "), bufio.ScanWords))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"hello", "xiter", "world"}, words)
+
+		runes, err := xiter.TryToSlice(xiter.ScanReader(strings.NewReader("你好"), bufio.ScanRunes))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"你", "好"}, runes)
+
+		errSplit := errors.New("split failed")
+		_, err = xiter.TryToSlice(xiter.ScanReader(strings.NewReader("abc"),
+			func(data []byte, atEOF bool) (int, []byte, error) {
+				return 0, nil, errSplit
+			}))
+		assert.Equal(t, errSplit, err)
+	})
+
+	t.Run("read records", func(t *testing.T) {
+		records, err := xiter.TryToSlice(xiter.ReadRecords(bytes.NewReader([]byte("aabbcc")), 2))
+		assert.Nil(t, err)
+		assert.Equal(t, [][]byte{[]byte("aa"), []byte("bb"), []byte("cc")}, records)
+
+		records, err = xiter.TryToSlice(xiter.ReadRecords(bytes.NewReader([]byte("aabbc")), 2, xiter.WithReadBufferSize(16)))
+		assert.Equal(t, io.ErrUnexpectedEOF, err)
+		assert.Equal(t, [][]byte{[]byte("aa"), []byte("bb")}, records)
+
+		records, err = xiter.TryToSlice(xiter.ReadRecords(&failReader{data: []byte("aab"), err: errRead}, 2))
+		assert.Equal(t, errRead, err)
+		assert.Equal(t, [][]byte{[]byte("aa")}, records)
+
+		records, err = xiter.TryToSlice(xiter.ReadRecords(bytes.NewReader(nil), 2))
+		assert.Nil(t, err)
+		assert.Len(t, records, 0)
+
+		assert.Panics(t, func() { xiter.ReadRecords(bytes.NewReader(nil), 0) })
+	})
+
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.go", "sub/c.go", "sub/deep/d.txt"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.Nil(t, os.WriteFile(path, []byte(name), 0o600))
+	}
+	entryName := func(e fs.DirEntry) (string, error) {
+		return e.Name(), nil
+	}
+
+	t.Run("dir entries", func(t *testing.T) {
+		names, err := xiter.TryToSlice(xiter.MapErr(entryName, xiter.DirEntries(dir)))
+		assert.Nil(t, err)
+		sort.Strings(names)
+		assert.Equal(t, []string{"a.txt", "b.go", "sub"}, names)
+
+		_, err = xiter.TryToSlice(xiter.DirEntries(filepath.Join(dir, "missing")))
+		assert.True(t, errors.Is(err, fs.ErrNotExist))
+
+		many := t.TempDir()
+		for i := 0; i < 300; i++ {
+			assert.Nil(t, os.WriteFile(filepath.Join(many, "f"+strconv.Itoa(i)), nil, 0o600))
+		}
+		entries, err := xiter.TryToSlice(xiter.DirEntries(many))
+		assert.Nil(t, err)
+		assert.Len(t, entries, 300)
+		assert.Len(t, xiter.ToSlice(xiter.Limit(xiter.Seq2KeyToSeq(xiter.SeqErrToSeq2(xiter.DirEntries(many))), 5)), 5)
+	})
+
+	t.Run("walk dir", func(t *testing.T) {
+		entries, err := xiter.TryToSlice(xiter.WalkDir(dir))
+		assert.Nil(t, err)
+		var paths []string
+		for _, e := range entries {
+			rel, _ := filepath.Rel(dir, e.Path)
+			paths = append(paths, filepath.ToSlash(rel))
+		}
+		assert.Equal(t, []string{".", "a.txt", "b.go", "sub", "sub/c.go", "sub/deep", "sub/deep/d.txt"}, paths)
+		assert.True(t, entries[0].IsDir())
+		assert.Equal(t, "a.txt", entries[1].Name())
+
+		goFiles, err := xiter.TryToSlice(xiter.FilterErr(func(e xiter.WalkEntry) (bool, error) {
+			return !e.IsDir() && filepath.Ext(e.Path) == ".go", nil
+		}, xiter.WalkDir(dir)))
+		assert.Nil(t, err)
+		assert.Len(t, goFiles, 2)
+
+		visited := 0
+		xiter.WalkDir(dir)(func(e xiter.WalkEntry, err error) bool {
+			visited++
+			return visited < 3
+		})
+		assert.Equal(t, 3, visited)
+
+		values, errs := xiter.CollectErrors(xiter.WalkDir(filepath.Join(dir, "missing")))
+		assert.Len(t, values, 0)
+		assert.Len(t, errs, 1)
+	})
+
+	t.Run("walk dir fs", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"a.txt":     {Data: []byte("a")},
+			"sub/b.txt": {Data: []byte("b")},
+		}
+		entries, err := xiter.TryToSlice(xiter.WalkDirFS(fsys, "."))
+		assert.Nil(t, err)
+		var paths []string
+		for _, e := range entries {
+			paths = append(paths, e.Path)
+		}
+		assert.Equal(t, []string{".", "a.txt", "sub", "sub/b.txt"}, paths)
+	})
+}
@@ -0,0 +1,206 @@
+package xiter
+
+import (
+	"github.com/dashjay/xiter/optional"
+	"github.com/dashjay/xiter/union"
+	"github.com/dashjay/xiter/xcmp"
+)
+
+// The *Join functions below join two sequences by keys like SQL joins.
+// The hash joins load right into a map and stream left, so right should be the smaller one,
+// the output follows the order of left, and the matches of an element follow the order of right.
+// A missing side of the outer joins is an empty optional.O.
+//
+// The *Join2 variants take Seq2 keyed by the join key, such as the output of MapToSeq2,
+// and yield the key with the joined pair.
+
+// HashJoin returns a Seq2 over the pairs of elements from left and right which have equal keys,
+// the keys are extracted by leftKey and rightKey.
+//
+// EXAMPLE:
+//
+//	users := xiter.FromSlice([]User{{ID: 1, Name: "alice"}, {ID: 2, Name: "bob"}})
+//	orders := xiter.FromSlice([]Order{{UserID: 1, Item: "book"}, {UserID: 1, Item: "pen"}})
+//	xiter.HashJoin(users, orders, func(u User) int { return u.ID }, func(o Order) int { return o.UserID })
+//	👉 [{alice book} {alice pen}]
+func HashJoin[L, R any, K comparable](left Seq[L], right Seq[R], leftKey func(L) K, rightKey func(R) K) Seq2[L, R] {
+	return dropJoinKey(HashJoin2(keyBy(left, leftKey), keyBy(right, rightKey)))
+}
+
+// HashJoin2 is like HashJoin but joins the Seq2 keyed by the join key.
+//
+// EXAMPLE:
+//
+//	left := xiter.MapToSeq2(xiter.FromSlice([]string{"a", "bb"}), func(s string) int { return len(s) })
+//	right := xiter.MapToSeq2Value(xiter.FromSlice([]int{1, 2}), func(v int) (int, int) { return v, v * 10 })
+//	xiter.HashJoin2(left, right) 👉 [1:{a 10} 2:{bb 20}]
+func HashJoin2[K comparable, L, R any](left Seq2[K, L], right Seq2[K, R]) Seq2[K, union.U2[L, R]] {
+	return func(yield func(K, union.U2[L, R]) bool) {
+		hashJoin(left, right, false, false)(func(k K, pair union.U2[optional.O[L], optional.O[R]]) bool {
+			return yield(k, union.U2[L, R]{T1: pair.T1.Must(), T2: pair.T2.Must()})
+		})
+	}
+}
+
+// LeftJoin is like HashJoin, but also yields the elements of left without a match, with an empty right side.
+//
+// EXAMPLE:
+//
+//	users := xiter.FromSlice([]User{{ID: 1, Name: "alice"}, {ID: 2, Name: "bob"}})
+//	orders := xiter.FromSlice([]Order{{UserID: 1, Item: "book"}})
+//	xiter.LeftJoin(users, orders, func(u User) int { return u.ID }, func(o Order) int { return o.UserID })
+//	👉 [{alice book} {bob <empty>}]
+func LeftJoin[L, R any, K comparable](left Seq[L], right Seq[R], leftKey func(L) K, rightKey func(R) K) Seq2[L, optional.O[R]] {
+	return dropJoinKey(LeftJoin2(keyBy(left, leftKey), keyBy(right, rightKey)))
+}
+
+// LeftJoin2 is like LeftJoin but joins the Seq2 keyed by the join key.
+func LeftJoin2[K comparable, L, R any](left Seq2[K, L], right Seq2[K, R]) Seq2[K, union.U2[L, optional.O[R]]] {
+	return func(yield func(K, union.U2[L, optional.O[R]]) bool) {
+		hashJoin(left, right, true, false)(func(k K, pair union.U2[optional.O[L], optional.O[R]]) bool {
+			return yield(k, union.U2[L, optional.O[R]]{T1: pair.T1.Must(), T2: pair.T2})
+		})
+	}
+}
+
+// FullOuterJoin is like LeftJoin, and after the elements of left,
+// it also yields the elements of right without a match in the order of right, with an empty left side.
+//
+// EXAMPLE:
+//
+//	users := xiter.FromSlice([]User{{ID: 1, Name: "alice"}, {ID: 2, Name: "bob"}})
+//	orders := xiter.FromSlice([]Order{{UserID: 1, Item: "book"}, {UserID: 3, Item: "pen"}})
+//	xiter.FullOuterJoin(users, orders, func(u User) int { return u.ID }, func(o Order) int { return o.UserID })
+//	👉 [{alice book} {bob <empty>} {<empty> pen}]
+func FullOuterJoin[L, R any, K comparable](left Seq[L], right Seq[R], leftKey func(L) K, rightKey func(R) K) Seq2[optional.O[L], optional.O[R]] {
+	return dropJoinKey(FullOuterJoin2(keyBy(left, leftKey), keyBy(right, rightKey)))
+}
+
+// FullOuterJoin2 is like FullOuterJoin but joins the Seq2 keyed by the join key.
+func FullOuterJoin2[K comparable, L, R any](left Seq2[K, L], right Seq2[K, R]) Seq2[K, union.U2[optional.O[L], optional.O[R]]] {
+	return hashJoin(left, right, true, true)
+}
+
+// SortMergeJoin is like HashJoin, but left and right must be sorted by their keys in ascending order.
+// Instead of loading right into a map, it walks both sequences at the same time,
+// and only buffers the elements of right with the same key. If the inputs are not sorted, the output is unspecified.
+// SortMergeJoin is equivalent to calling SortMergeJoinFunc with xcmp.Compare[K].
+//
+// EXAMPLE:
+//
+//	left := xiter.FromSlice([]int{1, 2, 2, 4})
+//	right := xiter.FromSlice([]string{"1", "2", "3"})
+//	xiter.SortMergeJoin(left, right, func(v int) int { return v }, func(s string) int { return int(s[0] - '0') })
+//	👉 [{1 1} {2 2} {2 2}]
+func SortMergeJoin[L, R any, K xcmp.Ordered](left Seq[L], right Seq[R], leftKey func(L) K, rightKey func(R) K) Seq2[L, R] {
+	return SortMergeJoinFunc(left, right, leftKey, rightKey, xcmp.Compare[K])
+}
+
+// SortMergeJoinFunc is like SortMergeJoin, but the keys are compared by f.
+func SortMergeJoinFunc[L, R, K any](left Seq[L], right Seq[R], leftKey func(L) K, rightKey func(R) K, f func(K, K) int) Seq2[L, R] {
+	return dropJoinKey(SortMergeJoinFunc2(keyBy(left, leftKey), keyBy(right, rightKey), f))
+}
+
+// SortMergeJoin2 is like SortMergeJoin but joins the Seq2 keyed by the join key.
+func SortMergeJoin2[K xcmp.Ordered, L, R any](left Seq2[K, L], right Seq2[K, R]) Seq2[K, union.U2[L, R]] {
+	return SortMergeJoinFunc2(left, right, xcmp.Compare[K])
+}
+
+// SortMergeJoinFunc2 is like SortMergeJoinFunc but joins the Seq2 keyed by the join key.
+// The key of left is yielded for every pair.
+func SortMergeJoinFunc2[K, L, R any](left Seq2[K, L], right Seq2[K, R], f func(K, K) int) Seq2[K, union.U2[L, R]] {
+	return func(yield func(K, union.U2[L, R]) bool) {
+		next, stop := Pull2(right)
+		defer stop()
+		rk, rv, rok := next()
+		// group holds the elements of right with the key groupKey, which may match more elements of left.
+		var group []R
+		var groupKey K
+		left(func(lk K, lv L) bool {
+			if len(group) == 0 || f(groupKey, lk) != 0 {
+				group = group[:0]
+				for rok && f(rk, lk) < 0 {
+					rk, rv, rok = next()
+				}
+				for rok && f(rk, lk) == 0 {
+					group = append(group, rv)
+					rk, rv, rok = next()
+				}
+				if len(group) == 0 {
+					// nothing in right can match the rest of left.
+					return rok
+				}
+				groupKey = lk
+			}
+			for _, r := range group {
+				if !yield(lk, union.U2[L, R]{T1: lv, T2: r}) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+}
+
+// hashJoin is the implementation of the hash joins, outerLeft and outerRight decide whether the elements
+// without a match in left and right are yielded.
+func hashJoin[K comparable, L, R any](left Seq2[K, L], right Seq2[K, R], outerLeft, outerRight bool) Seq2[K, union.U2[optional.O[L], optional.O[R]]] {
+	return func(yield func(K, union.U2[optional.O[L], optional.O[R]]) bool) {
+		var rights []union.U2[K, R]
+		index := make(map[K][]int)
+		right(func(k K, v R) bool {
+			index[k] = append(index[k], len(rights))
+			rights = append(rights, union.U2[K, R]{T1: k, T2: v})
+			return true
+		})
+		var matched []bool
+		if outerRight {
+			matched = make([]bool, len(rights))
+		}
+		stopped := false
+		left(func(k K, v L) bool {
+			indices, ok := index[k]
+			if !ok && outerLeft {
+				stopped = !yield(k, union.U2[optional.O[L], optional.O[R]]{T1: optional.FromValue(v), T2: optional.Empty[R]()})
+				return !stopped
+			}
+			for _, i := range indices {
+				if outerRight {
+					matched[i] = true
+				}
+				if !yield(k, union.U2[optional.O[L], optional.O[R]]{T1: optional.FromValue(v), T2: optional.FromValue(rights[i].T2)}) {
+					stopped = true
+					return false
+				}
+			}
+			return true
+		})
+		if stopped || !outerRight {
+			return
+		}
+		for i, r := range rights {
+			if !matched[i] && !yield(r.T1, union.U2[optional.O[L], optional.O[R]]{T1: optional.Empty[L](), T2: optional.FromValue(r.T2)}) {
+				return
+			}
+		}
+	}
+}
+
+// keyBy returns a Seq2 over the keys extracted by f and the elements of seq.
+// Unlike MapToSeq2, the keys do not have to be comparable.
+func keyBy[T, K any](seq Seq[T], f func(T) K) Seq2[K, T] {
+	return func(yield func(K, T) bool) {
+		seq(func(v T) bool {
+			return yield(f(v), v)
+		})
+	}
+}
+
+// dropJoinKey returns a Seq2 over the joined pairs of seq without their keys.
+func dropJoinKey[K, L, R any](seq Seq2[K, union.U2[L, R]]) Seq2[L, R] {
+	return func(yield func(L, R) bool) {
+		seq(func(_ K, pair union.U2[L, R]) bool {
+			return yield(pair.T1, pair.T2)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package xiter_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dashjay/xiter/optional"
+	"github.com/dashjay/xiter/union"
+	"github.com/dashjay/xiter/xiter"
+)
+
+type joinUser struct {
+	ID   int
+	Name string
+}
+
+type joinOrder struct {
+	UserID int
+	Item   string
+}
+
+func TestXIterJoin(t *testing.T) {
+	users := xiter.FromSlice([]joinUser{{1, "alice"}, {2, "bob"}, {3, "carol"}})
+	orders := xiter.FromSlice([]joinOrder{{1, "book"}, {4, "cup"}, {1, "pen"}, {3, "ink"}})
+	userID := func(u joinUser) int { return u.ID }
+	orderUserID := func(o joinOrder) int { return o.UserID }
+	name := func(o optional.O[joinUser]) string { return o.ValueOrZero().Name }
+	item := func(o optional.O[joinOrder]) string { return o.ValueOr(joinOrder{Item: "-"}).Item }
+
+	t.Run("hash join", func(t *testing.T) {
+		var got []string
+		xiter.HashJoin(users, orders, userID, orderUserID)(func(u joinUser, o joinOrder) bool {
+			got = append(got, u.Name+":"+o.Item)
+			return true
+		})
+		assert.Equal(t, []string{"alice:book", "alice:pen", "carol:ink"}, got)
+		testLimit2(t, xiter.HashJoin(users, orders, userID, orderUserID), 2)
+		assert.Equal(t, 0, xiter.Count(xiter.Seq2KeyToSeq(xiter.HashJoin(users, xiter.FromSlice([]joinOrder{}), userID, orderUserID))))
+
+		left := xiter.MapToSeq2(xiter.FromSlice([]string{"a", "bb", "cc"}), func(s string) int { return len(s) })
+		right := xiter.MapToSeq2Value(xiter.FromSlice([]int{1, 2}), func(v int) (int, int) { return v, v * 10 })
+		var keys []int
+		var pairs []union.U2[string, int]
+		xiter.HashJoin2(left, right)(func(k int, pair union.U2[string, int]) bool {
+			keys = append(keys, k)
+			pairs = append(pairs, pair)
+			return true
+		})
+		assert.Equal(t, []int{1, 2, 2}, keys)
+		assert.Equal(t, []union.U2[string, int]{{T1: "a", T2: 10}, {T1: "bb", T2: 20}, {T1: "cc", T2: 20}}, pairs)
+	})
+
+	t.Run("left join", func(t *testing.T) {
+		var got []string
+		xiter.LeftJoin(users, orders, userID, orderUserID)(func(u joinUser, o optional.O[joinOrder]) bool {
+			got = append(got, u.Name+":"+item(o))
+			return true
+		})
+		assert.Equal(t, []string{"alice:book", "alice:pen", "bob:-", "carol:ink"}, got)
+		testLimit2(t, xiter.LeftJoin(users, orders, userID, orderUserID), 3)
+
+		got = got[:0]
+		xiter.LeftJoin2(xiter.MapToSeq2(users, userID), xiter.MapToSeq2(orders, orderUserID))(
+			func(k int, pair union.U2[joinUser, optional.O[joinOrder]]) bool {
+				got = append(got, strconv.Itoa(k)+":"+pair.T1.Name+":"+item(pair.T2))
+				return true
+			})
+		assert.Equal(t, []string{"1:alice:book", "1:alice:pen", "2:bob:-", "3:carol:ink"}, got)
+	})
+
+	t.Run("full outer join", func(t *testing.T) {
+		var got []string
+		xiter.FullOuterJoin(users, orders, userID, orderUserID)(func(u optional.O[joinUser], o optional.O[joinOrder]) bool {
+			got = append(got, name(u)+":"+item(o))
+			return true
+		})
+		assert.Equal(t, []string{"alice:book", "alice:pen", "bob:-", "carol:ink", ":cup"}, got)
+		for i := 1; i <= 5; i++ {
+			testLimit2(t, xiter.FullOuterJoin(users, orders, userID, orderUserID), i)
+		}
+
+		var keys []int
+		xiter.FullOuterJoin2(xiter.MapToSeq2(users, userID), xiter.MapToSeq2(orders, orderUserID))(
+			func(k int, pair union.U2[optional.O[joinUser], optional.O[joinOrder]]) bool {
+				keys = append(keys, k)
+				return true
+			})
+		assert.Equal(t, []int{1, 1, 2, 3, 4}, keys)
+	})
+
+	t.Run("sort merge join", func(t *testing.T) {
+		sortedOrders := xiter.FromSlice([]joinOrder{{0, "mug"}, {1, "book"}, {1, "pen"}, {3, "ink"}, {4, "cup"}})
+		var got []string
+		xiter.SortMergeJoin(users, sortedOrders, userID, orderUserID)(func(u joinUser, o joinOrder) bool {
+			got = append(got, u.Name+":"+o.Item)
+			return true
+		})
+		assert.Equal(t, []string{"alice:book", "alice:pen", "carol:ink"}, got)
+		testLimit2(t, xiter.SortMergeJoin(users, sortedOrders, userID, orderUserID), 2)
+
+		// duplicated keys on both sides make a cross product
+		var pairs []union.U2[int, string]
+		left := xiter.FromSlice([]int{1, 2, 2, 4, 5})
+		right := xiter.FromSlice([]string{"2a", "2b", "3", "5"})
+		xiter.SortMergeJoin(left, right, func(v int) int { return v }, func(s string) int { return int(s[0] - '0') })(
+			func(l int, r string) bool {
+				pairs = append(pairs, union.U2[int, string]{T1: l, T2: r})
+				return true
+			})
+		assert.Equal(t, []union.U2[int, string]{
+			{T1: 2, T2: "2a"}, {T1: 2, T2: "2b"}, {T1: 2, T2: "2a"}, {T1: 2, T2: "2b"}, {T1: 5, T2: "5"},
+		}, pairs)
+
+		// right is exhausted early, left stops being pulled
+		pulled := 0
+		infinite := xiter.Map(func(v int) int {
+			pulled++
+			return v
+		}, xiter.Range(0, 1<<62, 1))
+		assert.Equal(t, 1, xiter.Count(xiter.Seq2KeyToSeq(xiter.SortMergeJoin(infinite, xiter.FromSlice([]int{3}),
+			func(v int) int { return v }, func(v int) int { return v }))))
+		assert.Equal(t, 5, pulled)
+
+		// descending order by f
+		desc := func(a, b int) int { return b - a }
+		var keys []int
+		xiter.SortMergeJoinFunc2(xiter.FromSliceIdx([]int{0, 0, 0}), xiter.MapToSeq2(xiter.FromSlice([]int{2, 1}),
+			func(v int) int { return v }), desc)(func(k int, _ union.U2[int, int]) bool {
+			keys = append(keys, k)
+			return true
+		})
+		assert.Len(t, keys, 0)
+		xiter.SortMergeJoinFunc2(xiter.MapToSeq2(xiter.FromSlice([]int{3, 2, 1}), func(v int) int { return v }),
+			xiter.MapToSeq2(xiter.FromSlice([]int{2, 1}), func(v int) int { return v }), desc)(
+			func(k int, _ union.U2[int, int]) bool {
+				keys = append(keys, k)
+				return true
+			})
+		assert.Equal(t, []int{2, 1}, keys)
+
+		keys = keys[:0]
+		xiter.SortMergeJoin2(xiter.MapToSeq2(xiter.FromSlice([]int{1, 2, 3}), func(v int) int { return v }),
+			xiter.MapToSeq2(xiter.FromSlice([]int{2, 3, 4}), func(v int) int { return v }))(
+			func(k int, _ union.U2[int, int]) bool {
+				keys = append(keys, k)
+				return true
+			})
+		assert.Equal(t, []int{2, 3}, keys)
+	})
+}
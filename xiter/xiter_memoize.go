@@ -0,0 +1,395 @@
+package xiter
+
+import (
+	"bufio"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// SpillPolicy decides what MemoizeBounded does with the elements beyond its capacity.
+type SpillPolicy int
+
+const (
+	// SpillRerun does not keep the elements beyond capacity,
+	// a replay which needs them runs seq again and skips the cached elements.
+	SpillRerun SpillPolicy = iota
+	// SpillDisk encodes the elements beyond capacity to a temp file with encoding/gob, and decodes them on replay.
+	// It panics if the temp file can not be written or read.
+	SpillDisk
+	// SpillPanic panics when seq has more elements than capacity.
+	SpillPanic
+)
+
+// Memoize returns a Seq that caches the elements of seq on the first pass and replays them later,
+// seq is run only once even if the first pass stops early, the next iteration continues pulling where it stopped.
+// So sources which can not run twice, like FromChan, can be iterated many times.
+//
+// All elements are cached in memory, use MemoizeBounded to limit the memory.
+// The source is consumed through Pull, stop releases it if it is not exhausted,
+// after stop only the cached elements are replayed. The returned Seq is safe for concurrent use,
+// the source is pulled without holding the lock, so an iteration waiting for a slow source does not block
+// the replays of the cached elements or stop, and the source is released when the pending pull returns.
+//
+// EXAMPLE:
+//
+//	ch := make(chan int, 3)
+//	ch <- 1; ch <- 2; ch <- 3
+//	close(ch)
+//	seq, stop := xiter.Memoize(xiter.FromChan(ch))
+//	defer stop()
+//	xiter.ToSlice(seq) 👉 [1 2 3]
+//	xiter.ToSlice(seq) 👉 [1 2 3]
+func Memoize[T any](seq Seq[T]) (Seq[T], func()) {
+	return memoize(seq, -1, SpillRerun)
+}
+
+// MemoizeBounded is like Memoize but caches at most capacity elements in memory,
+// the elements beyond capacity are handled by policy. It panics if capacity is negative.
+//
+// With SpillDisk, stop also removes the temp file, so it must always be called.
+//
+// EXAMPLE:
+//
+//	seq, stop := xiter.MemoizeBounded(readLines(file), 1000, xiter.SpillDisk)
+//	defer stop()
+//	// the first 1000 lines are replayed from memory and the rest from a temp file
+func MemoizeBounded[T any](seq Seq[T], capacity int, policy SpillPolicy) (Seq[T], func()) {
+	mustBeNonNegative("capacity", capacity)
+	return memoize(seq, capacity, policy)
+}
+
+type memo[T any] struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	seq      Seq[T]
+	capacity int // negative for unbounded
+	policy   SpillPolicy
+
+	next    func() (T, bool)
+	stopSrc func()
+	cache   []T
+	pulled  int
+	pulling bool // an iteration is pulling the source without holding mu
+	done    bool
+	stopped bool
+
+	file *os.File
+	w    *bufio.Writer
+	enc  *gob.Encoder
+}
+
+// memoReader reads the spilled elements in order for one iteration.
+type memoReader struct {
+	f    *os.File
+	dec  *gob.Decoder
+	read int // the number of spilled elements decoded
+}
+
+func memoize[T any](seq Seq[T], capacity int, policy SpillPolicy) (Seq[T], func()) {
+	m := &memo[T]{seq: seq, capacity: capacity, policy: policy}
+	m.cond = sync.NewCond(&m.mu)
+	return m.iterate, m.stop
+}
+
+func (m *memo[T]) iterate(yield func(T) bool) {
+	var r *memoReader
+	defer func() {
+		if r != nil {
+			_ = r.f.Close()
+		}
+	}()
+	for i := 0; ; i++ {
+		v, ok, rerun := m.get(i, &r)
+		if rerun {
+			skip := i
+			m.seq(func(v T) bool {
+				if skip > 0 {
+					skip--
+					return true
+				}
+				return yield(v)
+			})
+			return
+		}
+		if !ok || !yield(v) {
+			return
+		}
+	}
+}
+
+// get returns the element at i, rerun is true if the element is dropped by SpillRerun.
+func (m *memo[T]) get(i int, r **memoReader) (v T, ok bool, rerun bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for {
+		if i < len(m.cache) {
+			return m.cache[i], true, false
+		}
+		if m.stopped {
+			return v, false, false
+		}
+		if i < m.pulled {
+			if m.policy == SpillRerun {
+				return v, false, true
+			}
+			return m.readSpilled(r, i-m.capacity), true, false
+		}
+		if m.done {
+			return v, false, false
+		}
+		if !m.pulling {
+			break
+		}
+		m.cond.Wait()
+	}
+	if m.next == nil {
+		m.next, m.stopSrc = Pull(m.seq)
+	}
+	if v, ok = m.pull(); !ok {
+		m.done = true
+		m.stopSrc()
+		return v, false, false
+	}
+	if m.stopped {
+		// stop does not release the source while it is pulled.
+		m.stopSrc()
+		return v, false, false
+	}
+	m.pulled++
+	if m.capacity < 0 || len(m.cache) < m.capacity {
+		m.cache = append(m.cache, v)
+		return v, true, false
+	}
+	switch m.policy {
+	case SpillDisk:
+		m.writeSpilled(v)
+	case SpillPanic:
+		panic(fmt.Sprintf("xiter: memoized seq has more elements than capacity %d", m.capacity))
+	case SpillRerun:
+	}
+	return v, true, false
+}
+
+// pull calls next with mu unlocked, and wakes up the iterations waiting for it.
+func (m *memo[T]) pull() (T, bool) {
+	m.pulling = true
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		m.pulling = false
+		m.cond.Broadcast()
+	}()
+	return m.next()
+}
+
+func (m *memo[T]) writeSpilled(v T) {
+	if m.file == nil {
+		f, err := os.CreateTemp("", "xiter-memoize-*")
+		if err != nil {
+			panic(err)
+		}
+		m.file = f
+		m.w = bufio.NewWriter(f)
+		m.enc = gob.NewEncoder(m.w)
+	}
+	if err := m.enc.Encode(v); err != nil {
+		panic(err)
+	}
+}
+
+// readSpilled decodes the spilled element at j for the iteration of r.
+// The elements are always read in order, some may be skipped if the iteration pulled them from the source itself.
+func (m *memo[T]) readSpilled(r **memoReader, j int) (v T) {
+	if err := m.w.Flush(); err != nil {
+		panic(err)
+	}
+	if *r == nil {
+		f, err := os.Open(m.file.Name())
+		if err != nil {
+			panic(err)
+		}
+		*r = &memoReader{f: f, dec: gob.NewDecoder(bufio.NewReader(f))}
+	}
+	for ; (*r).read <= j; (*r).read++ {
+		var decoded T
+		if err := (*r).dec.Decode(&decoded); err != nil {
+			panic(err)
+		}
+		v = decoded
+	}
+	return v
+}
+
+func (m *memo[T]) stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopped {
+		return
+	}
+	m.stopped = true
+	if m.next != nil && !m.done && !m.pulling {
+		m.stopSrc()
+	}
+	if m.file != nil {
+		_ = m.file.Close()
+		_ = os.Remove(m.file.Name())
+	}
+	m.cond.Broadcast()
+}
+
+// Tee returns n Seqs which yield the same elements as seq, while seq is run only once.
+// Elements are buffered until all the consumers have read them, so the consumers are supposed to run concurrently.
+//
+// If bufSize is positive, at most bufSize elements are buffered, and a consumer ahead of the others by bufSize elements
+// blocks until the slowest one catches up, so consumers iterating one after another deadlock with a small bufSize.
+// A consumer which stops early no longer holds the others back. Each Seq can be iterated only once,
+// the next iterations yield nothing.
+//
+// The source is consumed through Pull, stop releases it and makes all consumers stop, including the blocked ones.
+// The source is pulled without holding the lock, so a consumer waiting for a slow source does not block stop,
+// and the source is released when the pending pull returns.
+//
+// EXAMPLE:
+//
+//	seqs, stop := xiter.Tee(xiter.FromSlice([]int{1, 2, 3}), 2, 0)
+//	defer stop()
+//	xiter.ToSlice(seqs[0]) 👉 [1 2 3]
+//	xiter.ToSlice(seqs[1]) 👉 [1 2 3]
+func Tee[T any](seq Seq[T], n int, bufSize int) ([]Seq[T], func()) {
+	mustBeNonNegative("n", n)
+	t := &tee[T]{seq: seq, limit: bufSize, pos: make([]int, n), started: make([]bool, n)}
+	t.cond = sync.NewCond(&t.mu)
+	seqs := make([]Seq[T], 0, n)
+	for i := 0; i < n; i++ {
+		i := i
+		seqs = append(seqs, func(yield func(T) bool) {
+			t.consume(i, yield)
+		})
+	}
+	return seqs, t.stop
+}
+
+type tee[T any] struct {
+	mu    sync.Mutex
+	cond  *sync.Cond
+	seq   Seq[T]
+	limit int
+
+	next    func() (T, bool)
+	stopSrc func()
+	buf     []T
+	base    int   // the index of buf[0] in seq
+	pos     []int // the index of the next element of every consumer, -1 if it is detached
+	started []bool
+	pulling bool // a consumer is pulling the source without holding mu
+	done    bool
+	stopped bool
+}
+
+func (t *tee[T]) consume(i int, yield func(T) bool) {
+	t.mu.Lock()
+	if t.started[i] {
+		t.mu.Unlock()
+		return
+	}
+	t.started[i] = true
+	t.mu.Unlock()
+	defer t.detach(i)
+	for {
+		v, ok := t.get(i)
+		if !ok || !yield(v) {
+			return
+		}
+	}
+}
+
+func (t *tee[T]) get(i int) (v T, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for {
+		if t.stopped {
+			return v, false
+		}
+		if p := t.pos[i]; p < t.base+len(t.buf) {
+			v = t.buf[p-t.base]
+			t.pos[i]++
+			t.trim()
+			return v, true
+		}
+		if t.done {
+			return v, false
+		}
+		if t.pulling || (t.limit > 0 && len(t.buf) >= t.limit) {
+			t.cond.Wait()
+			continue
+		}
+		if t.next == nil {
+			t.next, t.stopSrc = Pull(t.seq)
+		}
+		if v, ok = t.pull(); !ok {
+			t.done = true
+			t.stopSrc()
+			return v, false
+		}
+		if t.stopped {
+			// stop does not release the source while it is pulled.
+			t.stopSrc()
+			return v, false
+		}
+		t.buf = append(t.buf, v)
+	}
+}
+
+// pull calls next with mu unlocked, and wakes up the consumers waiting for it.
+func (t *tee[T]) pull() (T, bool) {
+	t.pulling = true
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		t.pulling = false
+		t.cond.Broadcast()
+	}()
+	return t.next()
+}
+
+// trim drops the elements read by all attached consumers, and wakes up the blocked ones.
+func (t *tee[T]) trim() {
+	low := t.base + len(t.buf)
+	for _, p := range t.pos {
+		if p >= 0 && p < low {
+			low = p
+		}
+	}
+	if k := low - t.base; k > 0 {
+		var zero T
+		for j := 0; j < k; j++ {
+			t.buf[j] = zero
+		}
+		t.buf = t.buf[k:]
+		t.base = low
+		t.cond.Broadcast()
+	}
+}
+
+func (t *tee[T]) detach(i int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pos[i] = -1
+	t.trim()
+}
+
+func (t *tee[T]) stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stopped {
+		return
+	}
+	t.stopped = true
+	t.buf = nil
+	if t.next != nil && !t.done && !t.pulling {
+		t.stopSrc()
+	}
+	t.cond.Broadcast()
+}
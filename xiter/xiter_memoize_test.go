@@ -0,0 +1,257 @@
+package xiter_test
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dashjay/xiter/xiter"
+)
+
+func TestXIterMemoize(t *testing.T) {
+	// counted returns a seq over [0, n) which counts how many elements are produced.
+	counted := func(n int, produced *int) xiter.Seq[int] {
+		return func(yield func(int) bool) {
+			for i := 0; i < n; i++ {
+				*produced++
+				if !yield(i) {
+					return
+				}
+			}
+		}
+	}
+
+	t.Run("memoize", func(t *testing.T) {
+		ch := make(chan int, 3)
+		ch <- 1
+		ch <- 2
+		ch <- 3
+		close(ch)
+		seq, stop := xiter.Memoize(xiter.FromChan(ch))
+		defer stop()
+		assert.Equal(t, []int{1, 2, 3}, xiter.ToSlice(seq))
+		assert.Equal(t, []int{1, 2, 3}, xiter.ToSlice(seq))
+		testLimit(t, seq, 1)
+
+		produced := 0
+		seq, stop = xiter.Memoize(counted(10, &produced))
+		assert.Equal(t, _range(0, 3), xiter.ToSlice(xiter.Limit(seq, 3)))
+		// the pre-go1.23 Pull may produce one more element ahead
+		firstPass := produced
+		assert.LessOrEqual(t, firstPass, 4)
+		assert.Equal(t, _range(0, 3), xiter.ToSlice(xiter.Limit(seq, 3)))
+		assert.Equal(t, firstPass, produced)
+		assert.Equal(t, _range(0, 5), xiter.ToSlice(xiter.Limit(seq, 5)))
+		assert.LessOrEqual(t, produced, 6)
+		assert.Equal(t, _range(0, 10), xiter.ToSlice(seq))
+		assert.Equal(t, _range(0, 10), xiter.ToSlice(seq))
+		assert.Equal(t, 10, produced)
+		stop()
+		assert.Equal(t, _range(0, 10), xiter.ToSlice(seq))
+	})
+
+	t.Run("memoize stop", func(t *testing.T) {
+		ng := stableNumGoroutine()
+		seq, stop := xiter.Memoize(xiter.Range(0, 1<<62, 1))
+		assert.Equal(t, _range(0, 3), xiter.ToSlice(xiter.Limit(seq, 3)))
+		stop()
+		stop()
+		assert.Equal(t, _range(0, 3), xiter.ToSlice(seq))
+		assert.Equal(t, ng, stableNumGoroutine())
+	})
+
+	t.Run("memoize concurrent", func(t *testing.T) {
+		seq, stop := xiter.Memoize(xiter.Range(0, 1000, 1))
+		defer stop()
+		var wg sync.WaitGroup
+		results := make([][]int, 8)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i] = xiter.ToSlice(seq)
+			}(i)
+		}
+		wg.Wait()
+		for _, res := range results {
+			assert.Equal(t, _range(0, 1000), res)
+		}
+	})
+
+	t.Run("memoize blocked source", func(t *testing.T) {
+		in := make(chan int, 1)
+		in <- 1
+		seq, stop := xiter.Memoize(xiter.FromChan(in))
+		assert.Equal(t, []int{1}, xiter.ToSlice(xiter.Limit(seq, 1)))
+		done := make(chan []int)
+		go func() {
+			// blocks on the idle channel after the cached element
+			done <- xiter.ToSlice(seq)
+		}()
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, []int{1}, xiter.ToSlice(xiter.Limit(seq, 1)))
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			stop()
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Fatal("stop blocks on a pending pull")
+		}
+		close(in)
+		assert.Equal(t, []int{1}, <-done)
+	})
+
+	t.Run("memoize bounded", func(t *testing.T) {
+		produced := 0
+		seq, stop := xiter.MemoizeBounded(counted(10, &produced), 4, xiter.SpillRerun)
+		assert.Equal(t, _range(0, 10), xiter.ToSlice(seq))
+		assert.Equal(t, 10, produced)
+		assert.Equal(t, _range(0, 3), xiter.ToSlice(xiter.Limit(seq, 3)))
+		assert.Equal(t, 10, produced)
+		assert.Equal(t, _range(0, 10), xiter.ToSlice(seq))
+		assert.Equal(t, 20, produced)
+		stop()
+
+		ch := make(chan int, 10)
+		for i := 0; i < 10; i++ {
+			ch <- i
+		}
+		close(ch)
+		seq, stop = xiter.MemoizeBounded(xiter.FromChan(ch), 4, xiter.SpillDisk)
+		assert.Equal(t, _range(0, 6), xiter.ToSlice(xiter.Limit(seq, 6)))
+		assert.Equal(t, _range(0, 10), xiter.ToSlice(seq))
+		assert.Equal(t, _range(0, 10), xiter.ToSlice(seq))
+		assert.Equal(t, _range(0, 10), xiter.ToSlice(seq))
+		stop()
+		assert.Equal(t, _range(0, 4), xiter.ToSlice(seq))
+
+		strs, stopStrs := xiter.MemoizeBounded(xiter.FromSlice([]string{"a", "b", "c"}), 0, xiter.SpillDisk)
+		assert.Equal(t, []string{"a", "b", "c"}, xiter.ToSlice(strs))
+		assert.Equal(t, []string{"a", "b", "c"}, xiter.ToSlice(strs))
+		stopStrs()
+
+		seq, stop = xiter.MemoizeBounded(xiter.Range(0, 10, 1), 4, xiter.SpillPanic)
+		assert.Equal(t, _range(0, 4), xiter.ToSlice(xiter.Limit(seq, 4)))
+		assert.Panics(t, func() { xiter.ToSlice(seq) })
+		stop()
+
+		assert.Panics(t, func() { xiter.MemoizeBounded(xiter.Range(0, 10, 1), -1, xiter.SpillPanic) })
+	})
+
+	t.Run("memoize bounded disk interleaved", func(t *testing.T) {
+		seq, stop := xiter.MemoizeBounded(xiter.Range(0, 100, 1), 10, xiter.SpillDisk)
+		defer stop()
+		next1, stop1 := xiter.Pull(seq)
+		defer stop1()
+		next2, stop2 := xiter.Pull(seq)
+		defer stop2()
+		var res1, res2 []int
+		for {
+			v1, ok1 := next1()
+			if ok1 {
+				res1 = append(res1, v1)
+			}
+			v2, ok2 := next2()
+			if ok2 {
+				res2 = append(res2, v2)
+			}
+			v2, ok2 = next2()
+			if ok2 {
+				res2 = append(res2, v2)
+			}
+			if !ok1 && !ok2 {
+				break
+			}
+		}
+		assert.Equal(t, _range(0, 100), res1)
+		assert.Equal(t, _range(0, 100), res2)
+	})
+
+	t.Run("tee", func(t *testing.T) {
+		produced := 0
+		seqs, stop := xiter.Tee(counted(10, &produced), 3, 0)
+		assert.Len(t, seqs, 3)
+		assert.Equal(t, _range(0, 10), xiter.ToSlice(seqs[0]))
+		assert.Equal(t, _range(0, 5), xiter.ToSlice(xiter.Limit(seqs[1], 5)))
+		assert.Equal(t, _range(0, 10), xiter.ToSlice(seqs[2]))
+		assert.Equal(t, 10, produced)
+		// each seq can be iterated only once
+		assert.Len(t, xiter.ToSlice(seqs[0]), 0)
+		stop()
+
+		seqs, stop = xiter.Tee(xiter.Range(0, 10, 1), 0, 0)
+		assert.Len(t, seqs, 0)
+		stop()
+	})
+
+	t.Run("tee bounded", func(t *testing.T) {
+		seqs, stop := xiter.Tee(xiter.Range(0, 10000, 1), 4, 8)
+		defer stop()
+		var wg sync.WaitGroup
+		results := make([][]int, len(seqs))
+		for i := range seqs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i] = xiter.ToSlice(seqs[i])
+			}(i)
+		}
+		wg.Wait()
+		for _, res := range results {
+			assert.Equal(t, _range(0, 10000), res)
+		}
+
+		// a consumer stopping early does not block the others
+		seqs, stop2 := xiter.Tee(xiter.Range(0, 100, 1), 2, 2)
+		defer stop2()
+		assert.Equal(t, []int{0}, xiter.ToSlice(xiter.Limit(seqs[0], 1)))
+		assert.Equal(t, _range(0, 100), xiter.ToSlice(seqs[1]))
+	})
+
+	t.Run("tee stop unblocks", func(t *testing.T) {
+		ng := stableNumGoroutine()
+		seqs, stop := xiter.Tee(xiter.Range(0, 1<<62, 1), 2, 4)
+		var got []int
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			// blocks after 4 elements, seqs[1] is never iterated
+			got = xiter.ToSlice(seqs[0])
+		}()
+		time.Sleep(20 * time.Millisecond)
+		stop()
+		<-done
+		sort.Ints(got)
+		assert.Equal(t, _range(0, 4), got)
+		assert.Len(t, xiter.ToSlice(seqs[1]), 0)
+		assert.Equal(t, ng, stableNumGoroutine())
+	})
+	t.Run("tee blocked source", func(t *testing.T) {
+		in := make(chan int)
+		seqs, stop := xiter.Tee(xiter.FromChan(in), 2, 0)
+		done := make(chan []int)
+		go func() {
+			done <- xiter.ToSlice(seqs[0])
+		}()
+		time.Sleep(20 * time.Millisecond)
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			stop()
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Fatal("stop blocks on a pending pull")
+		}
+		close(in)
+		assert.Len(t, <-done, 0)
+		assert.Len(t, xiter.ToSlice(seqs[1]), 0)
+	})
+}
@@ -0,0 +1,125 @@
+package xiter
+
+import (
+	"container/heap"
+
+	"github.com/dashjay/xiter/xcmp"
+)
+
+// MergeN merges any number of sequences of ordered values.
+// Like Merge but run with more than two sequences,
+// MergeN is equivalent to calling MergeNFunc with xcmp.Compare[V] as the ordering function.
+//
+// Example:
+//
+//	seq := xiter.MergeN(
+//		xiter.FromSlice([]int{1, 4, 7}),
+//		xiter.FromSlice([]int{2, 5, 8}),
+//		xiter.FromSlice([]int{3, 6, 9}),
+//	)
+//	fmt.Println(xiter.ToSlice(seq))
+//	// output:
+//	// [1 2 3 4 5 6 7 8 9]
+func MergeN[V xcmp.Ordered](seqs ...Seq[V]) Seq[V] {
+	return MergeNFunc(xcmp.Compare[V], seqs...)
+}
+
+// MergeNFunc merges any number of sequences of values ordered by the function f.
+// Values appear in the output once for each time they appear in seqs.
+// When equal values appear in more than one sequence,
+// the output contains the values from the earlier sequence in seqs first.
+//
+// Every sequence is consumed through Pull and the next values are kept in a heap,
+// so each output value costs O(log(len(seqs))) comparisons.
+// All the pulled sequences are stopped when the consumer stops iterating.
+func MergeNFunc[V any](f func(V, V) int, seqs ...Seq[V]) Seq[V] {
+	seqs2 := make([]Seq2[V, struct{}], 0, len(seqs))
+	for _, seq := range seqs {
+		seqs2 = append(seqs2, seqToKeySeq2(seq))
+	}
+	return Seq2KeyToSeq(MergeNFunc2(f, seqs2...))
+}
+
+// MergeN2 merges any number of sequences of key-value pairs ordered by their keys.
+// Like MergeN but run with Seq2
+func MergeN2[K xcmp.Ordered, V any](seqs ...Seq2[K, V]) Seq2[K, V] {
+	return MergeNFunc2(xcmp.Compare[K], seqs...)
+}
+
+// MergeNFunc2 merges any number of sequences of key-value pairs ordered by the function f.
+// Like MergeNFunc but run with Seq2
+func MergeNFunc2[K, V any](f func(K, K) int, seqs ...Seq2[K, V]) Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		stops := make([]func(), 0, len(seqs))
+		defer func() {
+			for _, stop := range stops {
+				stop()
+			}
+		}()
+		h := &mergeHeap[K, V]{cmp: f, sources: make([]*mergeSource[K, V], 0, len(seqs))}
+		for i, seq := range seqs {
+			next, stop := Pull2(seq)
+			stops = append(stops, stop)
+			if k, v, ok := next(); ok {
+				h.sources = append(h.sources, &mergeSource[K, V]{k: k, v: v, idx: i, next: next})
+			}
+		}
+		heap.Init(h)
+		for h.Len() > 0 {
+			top := h.sources[0]
+			if !yield(top.k, top.v) {
+				return
+			}
+			if k, v, ok := top.next(); ok {
+				top.k, top.v = k, v
+				heap.Fix(h, 0)
+			} else {
+				heap.Pop(h)
+			}
+		}
+	}
+}
+
+// mergeSource is a pulled sequence with its current key-value pair.
+type mergeSource[K, V any] struct {
+	k    K
+	v    V
+	idx  int
+	next func() (K, V, bool)
+}
+
+// mergeHeap implements heap.Interface, ties are broken by the index of the sequence to keep the merge stable.
+type mergeHeap[K, V any] struct {
+	cmp     func(K, K) int
+	sources []*mergeSource[K, V]
+}
+
+func (h *mergeHeap[K, V]) Len() int { return len(h.sources) }
+
+func (h *mergeHeap[K, V]) Less(i, j int) bool {
+	if c := h.cmp(h.sources[i].k, h.sources[j].k); c != 0 {
+		return c < 0
+	}
+	return h.sources[i].idx < h.sources[j].idx
+}
+
+func (h *mergeHeap[K, V]) Swap(i, j int) { h.sources[i], h.sources[j] = h.sources[j], h.sources[i] }
+
+func (h *mergeHeap[K, V]) Push(x any) { h.sources = append(h.sources, x.(*mergeSource[K, V])) }
+
+func (h *mergeHeap[K, V]) Pop() any {
+	n := len(h.sources)
+	x := h.sources[n-1]
+	h.sources[n-1] = nil
+	h.sources = h.sources[:n-1]
+	return x
+}
+
+// seqToKeySeq2 converts a Seq to a Seq2 whose keys are the elements of seq.
+func seqToKeySeq2[V any](seq Seq[V]) Seq2[V, struct{}] {
+	return func(yield func(V, struct{}) bool) {
+		seq(func(v V) bool {
+			return yield(v, struct{}{})
+		})
+	}
+}
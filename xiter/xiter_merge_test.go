@@ -0,0 +1,76 @@
+package xiter_test
+
+import (
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dashjay/xiter/xiter"
+)
+
+func TestXIterMergeN(t *testing.T) {
+	t.Run("merge n", func(t *testing.T) {
+		seq := xiter.MergeN(
+			xiter.FromSlice([]int{1, 4, 7}),
+			xiter.FromSlice([]int{2, 5, 8}),
+			xiter.FromSlice([]int{3, 6, 9}),
+		)
+		assert.Equal(t, _range(1, 10), xiter.ToSlice(seq))
+		testLimit(t, seq, 1)
+
+		assert.Len(t, xiter.ToSlice(xiter.MergeN[int]()), 0)
+		assert.Equal(t, _range(0, 3), xiter.ToSlice(xiter.MergeN(xiter.FromSlice(_range(0, 3)))))
+		assert.Equal(t, _range(0, 3), xiter.ToSlice(xiter.MergeN(xiter.FromSlice([]int{}), xiter.FromSlice(_range(0, 3)), xiter.FromSlice([]int{}))))
+
+		var all []int
+		var seqs []xiter.Seq[int]
+		for i := 0; i < 10; i++ {
+			shard := xiter.ToSlice(xiter.Range(i, 100, i+1))
+			all = append(all, shard...)
+			seqs = append(seqs, xiter.FromSlice(shard))
+		}
+		sort.Ints(all)
+		assert.Equal(t, all, xiter.ToSlice(xiter.MergeN(seqs...)))
+	})
+
+	t.Run("merge n func", func(t *testing.T) {
+		desc := func(a, b int) int { return b - a }
+		seq := xiter.MergeNFunc(desc, xiter.FromSlice([]int{9, 3}), xiter.FromSlice([]int{8, 2}), xiter.FromSlice([]int{7, 1}))
+		assert.Equal(t, []int{9, 8, 7, 3, 2, 1}, xiter.ToSlice(seq))
+	})
+
+	t.Run("merge n2 stable", func(t *testing.T) {
+		tag := func(name string, keys ...int) xiter.Seq2[int, string] {
+			return xiter.MapToSeq2Value(xiter.FromSlice(keys), func(k int) (int, string) { return k, name })
+		}
+		seq := xiter.MergeN2(tag("a", 1, 2, 2), tag("b", 1, 2), tag("c", 0, 2))
+		assert.Equal(t, []int{0, 1, 1, 2, 2, 2, 2}, xiter.ToSliceSeq2Key(seq))
+		assert.Equal(t, []string{"c", "a", "b", "a", "a", "b", "c"}, xiter.ToSliceSeq2Value(seq))
+		testLimit2(t, seq, 3)
+
+		desc := func(a, b int) int { return b - a }
+		seq = xiter.MergeNFunc2(desc, tag("a", 2, 1), tag("b", 2))
+		assert.Equal(t, []string{"a", "b", "a"}, xiter.ToSliceSeq2Value(seq))
+	})
+
+	t.Run("merge n stop", func(t *testing.T) {
+		var stopped int64
+		source := func(start int) xiter.Seq[int] {
+			return func(yield func(int) bool) {
+				defer atomic.AddInt64(&stopped, 1)
+				for i := start; ; i += 3 {
+					if !yield(i) {
+						return
+					}
+				}
+			}
+		}
+		seq := xiter.MergeN(source(0), source(1), source(2))
+		assert.Equal(t, _range(0, 10), xiter.ToSlice(xiter.Limit(seq, 10)))
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, int64(3), atomic.LoadInt64(&stopped))
+	})
+}
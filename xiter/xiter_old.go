@@ -4,7 +4,6 @@
 package xiter
 
 import (
-	"math/rand"
 	"runtime"
 	"strconv"
 	"strings"
@@ -22,8 +21,8 @@ type Seq2[K, V any] func(yield func(K, V) bool)
 
 func Concat[V any](seqs ...Seq[V]) Seq[V] {
 	return func(yield func(V) bool) {
-		contine := false
 		for _, seq := range seqs {
+			contine := true
 			seq(func(v V) bool {
 				contine = yield(v)
 				return contine
@@ -36,9 +35,9 @@ func Concat[V any](seqs ...Seq[V]) Seq[V] {
 }
 
 func Concat2[K, V any](seqs ...Seq2[K, V]) Seq2[K, V] {
-	contine := false
 	return func(yield func(K, V) bool) {
 		for _, seq := range seqs {
+			contine := true
 			seq(func(k K, v V) bool {
 				contine = yield(k, v)
 				return contine
@@ -123,6 +122,7 @@ func Filter2[K, V any](f func(K, V) bool, seq Seq2[K, V]) Seq2[K, V] {
 
 func Limit[V any](seq Seq[V], n int) Seq[V] {
 	return func(yield func(V) bool) {
+		n := n
 		if n <= 0 {
 			return
 		}
@@ -143,6 +143,7 @@ func Limit[V any](seq Seq[V], n int) Seq[V] {
 
 func Limit2[K, V any](seq Seq2[K, V], n int) Seq2[K, V] {
 	return func(yield func(K, V) bool) {
+		n := n
 		if n <= 0 {
 			return
 		}
@@ -186,19 +187,22 @@ func MergeFunc[V any](x, y Seq[V], f func(V, V) int) Seq[V] {
 		next, stop := Pull(y)
 		defer stop()
 		v2, ok2 := next()
+		stopped := false
 		x(func(v1 V) bool {
 			for ok2 && f(v1, v2) > 0 {
 				if !yield(v2) {
+					stopped = true
 					return false
 				}
 				v2, ok2 = next()
 			}
 			if !yield(v1) {
+				stopped = true
 				return false
 			}
 			return true
 		})
-		for ok2 {
+		for !stopped && ok2 {
 			if !yield(v2) {
 				return
 			}
@@ -216,19 +220,22 @@ func MergeFunc2[K, V any](x, y Seq2[K, V], f func(K, K) int) Seq2[K, V] {
 		next, stop := Pull2(y)
 		defer stop()
 		k2, v2, ok2 := next()
+		stopped := false
 		x(func(k1 K, v1 V) bool {
 			for ok2 && f(k1, k2) > 0 {
 				if !yield(k2, v2) {
+					stopped = true
 					return false
 				}
 				k2, v2, ok2 = next()
 			}
 			if !yield(k1, v1) {
+				stopped = true
 				return false
 			}
 			return true
 		})
-		for ok2 {
+		for !stopped && ok2 {
 			if !yield(k2, v2) {
 				return
 			}
@@ -258,8 +265,10 @@ func Zip[V1, V2 any](x Seq[V1], y Seq[V2]) Seq[Zipped[V1, V2]] {
 		next, stop := Pull(y)
 		defer stop()
 		v2, ok2 := next()
+		stopped := false
 		x(func(v1 V1) bool {
 			if !yield(Zipped[V1, V2]{v1, true, v2, ok2}) {
+				stopped = true
 				return false
 			}
 			v2, ok2 = next()
@@ -267,7 +276,7 @@ func Zip[V1, V2 any](x Seq[V1], y Seq[V2]) Seq[Zipped[V1, V2]] {
 		})
 
 		var zv1 V1
-		for ok2 {
+		for !stopped && ok2 {
 			if !yield(Zipped[V1, V2]{zv1, false, v2, ok2}) {
 				return
 			}
@@ -281,8 +290,10 @@ func Zip2[K1, V1, K2, V2 any](x Seq2[K1, V1], y Seq2[K2, V2]) Seq[Zipped2[K1, V1
 		next, stop := Pull2(y)
 		defer stop()
 		k2, v2, ok2 := next()
+		stopped := false
 		x(func(k1 K1, v1 V1) bool {
 			if !yield(Zipped2[K1, V1, K2, V2]{k1, v1, true, k2, v2, ok2}) {
+				stopped = true
 				return false
 			}
 			k2, v2, ok2 = next()
@@ -291,7 +302,7 @@ func Zip2[K1, V1, K2, V2 any](x Seq2[K1, V1], y Seq2[K2, V2]) Seq[Zipped2[K1, V1
 
 		var zk1 K1
 		var zv1 V1
-		for ok2 {
+		for !stopped && ok2 {
 			if !yield(Zipped2[K1, V1, K2, V2]{zk1, zv1, false, k2, v2, ok2}) {
 				return
 			}
@@ -799,6 +810,7 @@ func ToSliceN[T any](seq Seq[T], n int) (out []T) {
 
 func Skip[T any](seq Seq[T], n int) Seq[T] {
 	return func(yield func(T) bool) {
+		n := n
 		seq(func(v T) bool {
 			if n == 0 {
 				return yield(v)
@@ -835,8 +847,8 @@ func ReplaceAll[T comparable](seq Seq[T], from, to T) Seq[T] {
 	return Replace(seq, from, to, -1)
 }
 
-func FromSliceShuffle[T any](in []T) Seq[T] {
-	randPerm := rand.Perm(len(in))
+func FromSliceShuffle[T any](in []T, opts ...RandOption) Seq[T] {
+	randPerm := newRandOptions(opts).Perm(len(in))
 	return func(yield func(T) bool) {
 		for i := 0; i < len(randPerm); i++ {
 			if !yield(in[randPerm[i]]) {
@@ -982,3 +994,192 @@ func Compact[T comparable](in Seq[T]) Seq[T] {
 		})
 	}
 }
+
+type SeqErr[V any] func(yield func(V, error) bool)
+
+func SeqToSeqErr[V any](seq Seq[V]) SeqErr[V] {
+	return func(yield func(V, error) bool) {
+		seq(func(v V) bool {
+			return yield(v, nil)
+		})
+	}
+}
+
+func Seq2ToSeqErr[V any](seq Seq2[V, error]) SeqErr[V] {
+	return SeqErr[V](seq)
+}
+
+func SeqErrToSeq2[V any](seq SeqErr[V]) Seq2[V, error] {
+	return Seq2[V, error](seq)
+}
+
+func MapErr[In, Out any](f func(In) (Out, error), seq SeqErr[In]) SeqErr[Out] {
+	return func(yield func(Out, error) bool) {
+		var zero Out
+		seq(func(in In, err error) bool {
+			if err != nil {
+				yield(zero, err)
+				return false
+			}
+			out, err := f(in)
+			if err != nil {
+				yield(zero, err)
+				return false
+			}
+			return yield(out, nil)
+		})
+	}
+}
+
+func FilterErr[V any](f func(V) (bool, error), seq SeqErr[V]) SeqErr[V] {
+	return func(yield func(V, error) bool) {
+		var zero V
+		seq(func(v V, err error) bool {
+			if err != nil {
+				yield(zero, err)
+				return false
+			}
+			ok, err := f(v)
+			if err != nil {
+				yield(zero, err)
+				return false
+			}
+			if ok && !yield(v, nil) {
+				return false
+			}
+			return true
+		})
+	}
+}
+
+func TryReduce[Sum, V any](f func(Sum, V) (Sum, error), sum Sum, seq SeqErr[V]) (Sum, error) {
+	var outErr error
+	seq(func(v V, err error) bool {
+		if err != nil {
+			outErr = err
+			return false
+		}
+		next, err := f(sum, v)
+		if err != nil {
+			outErr = err
+			return false
+		}
+		sum = next
+		return true
+	})
+	return sum, outErr
+}
+
+func TryToSlice[V any](seq SeqErr[V]) (out []V, err error) {
+	seq(func(v V, e error) bool {
+		if e != nil {
+			err = e
+			return false
+		}
+		out = append(out, v)
+		return true
+	})
+	return out, err
+}
+
+func CollectErrors[V any](seq SeqErr[V]) (out []V, errs []error) {
+	seq(func(v V, err error) bool {
+		if err != nil {
+			errs = append(errs, err)
+			return true
+		}
+		out = append(out, v)
+		return true
+	})
+	return out, errs
+}
+
+func TakeWhile[V any](f func(V) bool, seq Seq[V]) Seq[V] {
+	return func(yield func(V) bool) {
+		seq(func(v V) bool {
+			return f(v) && yield(v)
+		})
+	}
+}
+
+func TakeWhile2[K, V any](f func(K, V) bool, seq Seq2[K, V]) Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		seq(func(k K, v V) bool {
+			return f(k, v) && yield(k, v)
+		})
+	}
+}
+
+func TakeUntil[V any](f func(V) bool, seq Seq[V]) Seq[V] {
+	return func(yield func(V) bool) {
+		seq(func(v V) bool {
+			return yield(v) && !f(v)
+		})
+	}
+}
+
+func TakeUntil2[K, V any](f func(K, V) bool, seq Seq2[K, V]) Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		seq(func(k K, v V) bool {
+			return yield(k, v) && !f(k, v)
+		})
+	}
+}
+
+func DropWhile[V any](f func(V) bool, seq Seq[V]) Seq[V] {
+	return func(yield func(V) bool) {
+		dropping := true
+		seq(func(v V) bool {
+			if dropping && f(v) {
+				return true
+			}
+			dropping = false
+			return yield(v)
+		})
+	}
+}
+
+func DropWhile2[K, V any](f func(K, V) bool, seq Seq2[K, V]) Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		dropping := true
+		seq(func(k K, v V) bool {
+			if dropping && f(k, v) {
+				return true
+			}
+			dropping = false
+			return yield(k, v)
+		})
+	}
+}
+
+func SkipWhile[V any](f func(V) bool, seq Seq[V]) Seq[V] {
+	return DropWhile(f, seq)
+}
+
+func SkipWhile2[K, V any](f func(K, V) bool, seq Seq2[K, V]) Seq2[K, V] {
+	return DropWhile2(f, seq)
+}
+
+func SplitAt[V any](seq Seq[V], n int) (prefix Seq[V], rest Seq[V], stop func()) {
+	prefix2, rest2, stop := SplitAt2(seqToKeySeq2(seq), n)
+	return Seq2KeyToSeq(prefix2), Seq2KeyToSeq(rest2), stop
+}
+
+func SplitAt2[K, V any](seq Seq2[K, V], n int) (prefix Seq2[K, V], rest Seq2[K, V], stop func()) {
+	i := 0
+	return split2(seq, func(K, V) bool {
+		i++
+		return i <= n
+	})
+}
+
+func Span[V any](f func(V) bool, seq Seq[V]) (prefix Seq[V], rest Seq[V], stop func()) {
+	prefix2, rest2, stop := Span2(func(v V, _ struct{}) bool {
+		return f(v)
+	}, seqToKeySeq2(seq))
+	return Seq2KeyToSeq(prefix2), Seq2KeyToSeq(rest2), stop
+}
+
+func Span2[K, V any](f func(K, V) bool, seq Seq2[K, V]) (prefix Seq2[K, V], rest Seq2[K, V], stop func()) {
+	return split2(seq, f)
+}
@@ -0,0 +1,243 @@
+package xiter
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/panjf2000/ants/v2"
+)
+
+// ParallelOption configures how ParallelMap, ParallelFilter and ParallelForEach run.
+type ParallelOption func(*parallelOptions)
+
+type parallelOptions struct {
+	size    int
+	pool    *ants.Pool
+	ordered bool
+}
+
+// WithPoolSize sets the max number of elements processed at the same time, default is runtime.GOMAXPROCS(0).
+// Non-positive n is ignored.
+func WithPoolSize(n int) ParallelOption {
+	return func(o *parallelOptions) {
+		if n > 0 {
+			o.size = n
+		}
+	}
+}
+
+// WithPool makes the workers run on the caller-supplied pool instead of a pool created for each iteration.
+// The pool is not released after iteration. If WithPoolSize is not set, the capacity of pool is used as the pool size.
+func WithPool(pool *ants.Pool) ParallelOption {
+	return func(o *parallelOptions) {
+		o.pool = pool
+	}
+}
+
+// WithOrdered sets whether the results keep the order of the input, default is true.
+// When ordered is false, the results are yielded as soon as they are completed.
+func WithOrdered(ordered bool) ParallelOption {
+	return func(o *parallelOptions) {
+		o.ordered = ordered
+	}
+}
+
+func newParallelOptions(opts []ParallelOption) *parallelOptions {
+	o := &parallelOptions{ordered: true}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.size <= 0 {
+		if o.pool != nil && o.pool.Cap() > 0 {
+			o.size = o.pool.Cap()
+		} else {
+			o.size = runtime.GOMAXPROCS(0)
+		}
+	}
+	return o
+}
+
+// ParallelMap returns a Seq over the results of applying f to each value in seq,
+// f is called concurrently on a bounded ants worker pool.
+//
+// By default, results are yielded in the order of seq, use WithOrdered(false) to yield them as-completed.
+// If f or seq panics, the panic is propagated to the consumer.
+// When the consumer stops iterating, no more elements are pulled from seq, and ParallelMap waits for
+// the running workers to finish before returning, so no goroutine is leaked.
+//
+// Example:
+//
+//	seq := xiter.FromSlice([]int{1, 2, 3})
+//	doubled := xiter.ParallelMap(func(v int) int { return v * 2 }, seq, xiter.WithPoolSize(2))
+//	fmt.Println(xiter.ToSlice(doubled))
+//	// output:
+//	// [2 4 6]
+func ParallelMap[In, Out any](f func(In) Out, seq Seq[In], opts ...ParallelOption) Seq[Out] {
+	return parallelRun(seq, func(in In) (Out, bool) {
+		return f(in), true
+	}, opts)
+}
+
+// ParallelFilter returns a Seq over seq that only includes the values v for which f(v) is true,
+// f is called concurrently on a bounded ants worker pool.
+// Like ParallelMap, the options control pool size and ordering.
+//
+// Example:
+//
+//	seq := xiter.FromSlice([]int{1, 2, 3, 4, 5})
+//	even := xiter.ParallelFilter(func(v int) bool { return v%2 == 0 }, seq)
+//	fmt.Println(xiter.ToSlice(even))
+//	// output:
+//	// [2 4]
+func ParallelFilter[V any](f func(V) bool, seq Seq[V], opts ...ParallelOption) Seq[V] {
+	return parallelRun(seq, func(v V) (V, bool) {
+		return v, f(v)
+	}, opts)
+}
+
+// ParallelForEach execute f for each element in seq concurrently on a bounded ants worker pool.
+// No more elements are dispatched once any f returns false, it returns after all dispatched f returned.
+//
+// Example:
+//
+//	var sum int64
+//	xiter.ParallelForEach(xiter.Range(0, 100, 1), func(v int) bool {
+//		atomic.AddInt64(&sum, int64(v))
+//		return true
+//	})
+//	// sum is 4950
+func ParallelForEach[T any](seq Seq[T], f func(T) bool, opts ...ParallelOption) {
+	parallelRun(seq, func(v T) (bool, bool) {
+		return f(v), true
+	}, opts)(func(c bool) bool {
+		return c
+	})
+}
+
+// parallelResult is the result of one task, keep is false when the result should be dropped.
+type parallelResult[T any] struct {
+	v          T
+	keep       bool
+	panicked   bool
+	panicValue any
+}
+
+func parallelCall[In, Out any](f func(In) (Out, bool), in In) (r parallelResult[Out]) {
+	defer func() {
+		if p := recover(); p != nil {
+			r.panicked = true
+			r.panicValue = p
+		}
+	}()
+	r.v, r.keep = f(in)
+	return r
+}
+
+func parallelRun[In, Out any](seq Seq[In], f func(In) (Out, bool), opts []ParallelOption) Seq[Out] {
+	o := newParallelOptions(opts)
+	return func(yield func(Out) bool) {
+		pool := o.pool
+		if pool == nil {
+			p, err := ants.NewPool(o.size)
+			if err != nil {
+				panic(err)
+			}
+			defer p.Release()
+			pool = p
+		}
+		// submit runs task on pool, or on the calling goroutine if pool refused it (overloaded or closed).
+		submit := func(task func()) {
+			if err := pool.Submit(task); err != nil {
+				task()
+			}
+		}
+
+		done := make(chan struct{})
+		var producer sync.WaitGroup
+		defer func() {
+			close(done)
+			producer.Wait()
+		}()
+
+		var seqPanicked bool
+		var seqPanicValue any
+		recoverSeq := func() {
+			if p := recover(); p != nil {
+				seqPanicked = true
+				seqPanicValue = p
+			}
+		}
+
+		if o.ordered {
+			// every element gets a slot, slots are consumed in the order of seq.
+			slots := make(chan chan parallelResult[Out], o.size)
+			producer.Add(1)
+			go func() {
+				defer producer.Done()
+				defer close(slots)
+				defer recoverSeq()
+				seq(func(in In) bool {
+					slot := make(chan parallelResult[Out], 1)
+					select {
+					case slots <- slot:
+					case <-done:
+						return false
+					}
+					producer.Add(1)
+					submit(func() {
+						defer producer.Done()
+						slot <- parallelCall(f, in)
+					})
+					return true
+				})
+			}()
+			for slot := range slots {
+				r := <-slot
+				if r.panicked {
+					panic(r.panicValue)
+				}
+				if r.keep && !yield(r.v) {
+					return
+				}
+			}
+		} else {
+			// sem is released when a result is read, at most o.size tasks are running or holding an unread result,
+			// so sending to results never blocks.
+			results := make(chan parallelResult[Out], o.size)
+			sem := make(chan struct{}, o.size)
+			producer.Add(1)
+			go func() {
+				var tasks sync.WaitGroup
+				defer producer.Done()
+				defer close(results)
+				defer tasks.Wait()
+				defer recoverSeq()
+				seq(func(in In) bool {
+					select {
+					case sem <- struct{}{}:
+					case <-done:
+						return false
+					}
+					tasks.Add(1)
+					submit(func() {
+						defer tasks.Done()
+						results <- parallelCall(f, in)
+					})
+					return true
+				})
+			}()
+			for r := range results {
+				<-sem
+				if r.panicked {
+					panic(r.panicValue)
+				}
+				if r.keep && !yield(r.v) {
+					return
+				}
+			}
+		}
+		if seqPanicked {
+			panic(seqPanicValue)
+		}
+	}
+}
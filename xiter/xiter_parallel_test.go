@@ -0,0 +1,124 @@
+package xiter_test
+
+import (
+	"runtime"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/panjf2000/ants/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dashjay/xiter/xiter"
+)
+
+func TestXIterParallel(t *testing.T) {
+	double := func(v int) int {
+		return v * 2
+	}
+
+	t.Run("parallel map", func(t *testing.T) {
+		seq := xiter.ParallelMap(double, xiter.FromSlice(_range(0, 1000)), xiter.WithPoolSize(8))
+		assert.Equal(t, xiter.ToSlice(xiter.Map(double, xiter.FromSlice(_range(0, 1000)))), xiter.ToSlice(seq))
+		testLimit(t, seq, 1)
+
+		unordered := xiter.ToSlice(xiter.ParallelMap(double, xiter.FromSlice(_range(0, 1000)), xiter.WithOrdered(false)))
+		sort.Ints(unordered)
+		assert.Equal(t, xiter.ToSlice(xiter.Map(double, xiter.FromSlice(_range(0, 1000)))), unordered)
+
+		assert.Len(t, xiter.ToSlice(xiter.ParallelMap(double, xiter.FromSlice([]int{}))), 0)
+	})
+
+	t.Run("parallel map as-completed", func(t *testing.T) {
+		slow := func(v int) int {
+			if v == 0 {
+				time.Sleep(50 * time.Millisecond)
+			}
+			return v
+		}
+		res := xiter.ToSlice(xiter.ParallelMap(slow, xiter.FromSlice(_range(0, 4)), xiter.WithPoolSize(4), xiter.WithOrdered(false)))
+		assert.Equal(t, 0, res[len(res)-1])
+		res = xiter.ToSlice(xiter.ParallelMap(slow, xiter.FromSlice(_range(0, 4)), xiter.WithPoolSize(4)))
+		assert.Equal(t, _range(0, 4), res)
+	})
+
+	t.Run("parallel filter", func(t *testing.T) {
+		even := func(v int) bool {
+			return v%2 == 0
+		}
+		seq := xiter.ParallelFilter(even, xiter.FromSlice(_range(0, 100)))
+		assert.Equal(t, xiter.ToSlice(xiter.Filter(even, xiter.FromSlice(_range(0, 100)))), xiter.ToSlice(seq))
+
+		unordered := xiter.ToSlice(xiter.ParallelFilter(even, xiter.FromSlice(_range(0, 100)), xiter.WithOrdered(false)))
+		sort.Ints(unordered)
+		assert.Equal(t, xiter.ToSlice(xiter.Filter(even, xiter.FromSlice(_range(0, 100)))), unordered)
+	})
+
+	t.Run("parallel for each", func(t *testing.T) {
+		var sum int64
+		xiter.ParallelForEach(xiter.FromSlice(_range(0, 100)), func(v int) bool {
+			atomic.AddInt64(&sum, int64(v))
+			return true
+		})
+		assert.Equal(t, int64(4950), sum)
+
+		var calls int64
+		xiter.ParallelForEach(xiter.Generate(func() int { return 1 }), func(v int) bool {
+			return atomic.AddInt64(&calls, 1) < 10
+		}, xiter.WithPoolSize(2))
+		assert.GreaterOrEqual(t, atomic.LoadInt64(&calls), int64(10))
+	})
+
+	t.Run("custom pool", func(t *testing.T) {
+		pool, err := ants.NewPool(3)
+		assert.Nil(t, err)
+		defer pool.Release()
+		seq := xiter.ParallelMap(double, xiter.FromSlice(_range(0, 100)), xiter.WithPool(pool))
+		assert.Equal(t, xiter.ToSlice(xiter.Map(double, xiter.FromSlice(_range(0, 100)))), xiter.ToSlice(seq))
+		assert.False(t, pool.IsClosed())
+
+		// a released pool refuses tasks, they run on the producer instead
+		released, err := ants.NewPool(1)
+		assert.Nil(t, err)
+		released.Release()
+		seq = xiter.ParallelMap(double, xiter.FromSlice(_range(0, 10)), xiter.WithPool(released))
+		assert.Equal(t, xiter.ToSlice(xiter.Map(double, xiter.FromSlice(_range(0, 10)))), xiter.ToSlice(seq))
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		for _, ordered := range []bool{true, false} {
+			assert.PanicsWithValue(t, "boom", func() {
+				xiter.ToSlice(xiter.ParallelMap(func(v int) int {
+					if v == 50 {
+						panic("boom")
+					}
+					return v
+				}, xiter.FromSlice(_range(0, 100)), xiter.WithOrdered(ordered)))
+			})
+			assert.PanicsWithValue(t, "boom", func() {
+				xiter.ToSlice(xiter.ParallelMap(double, func(yield func(int) bool) {
+					yield(1)
+					panic("boom")
+				}, xiter.WithOrdered(ordered)))
+			})
+		}
+	})
+
+	t.Run("break without leak", func(t *testing.T) {
+		ng := stableNumGoroutine()
+		for _, ordered := range []bool{true, false} {
+			var pulled int64
+			gen := xiter.Generate(func() int {
+				return int(atomic.AddInt64(&pulled, 1))
+			})
+			seq := xiter.ParallelMap(double, gen, xiter.WithPoolSize(4), xiter.WithOrdered(ordered))
+			assert.Len(t, xiter.ToSlice(xiter.Limit(seq, 10)), 10)
+			p := atomic.LoadInt64(&pulled)
+			time.Sleep(10 * time.Millisecond)
+			assert.Equal(t, p, atomic.LoadInt64(&pulled))
+		}
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, ng, runtime.NumGoroutine())
+	})
+}
@@ -0,0 +1,178 @@
+package xiter
+
+import "github.com/dashjay/xiter/union"
+
+// Peekable wraps a Seq with lookahead and pushback, which is useful for parsers.
+// The Seq is consumed through Pull, so Stop must be called if the Seq is not consumed to the end.
+// A Peekable is not safe for concurrent use.
+//
+// EXAMPLE:
+//
+//	p := xiter.NewPeekable(xiter.FromSlice([]int{1, 2, 3, 10, 11}))
+//	defer p.Stop()
+//	var small []int
+//	for v, ok := p.NextIf(func(v int) bool { return v < 10 }); ok; v, ok = p.NextIf(func(v int) bool { return v < 10 }) {
+//		small = append(small, v)
+//	}
+//	small 👉 [1 2 3]
+//	xiter.ToSlice(p.Seq()) 👉 [10 11]
+type Peekable[T any] struct {
+	p *Peekable2[T, struct{}]
+}
+
+// NewPeekable returns a Peekable over seq.
+func NewPeekable[T any](seq Seq[T]) *Peekable[T] {
+	return &Peekable[T]{p: NewPeekable2(seqToKeySeq2(seq))}
+}
+
+// Next returns the next element and advances, ok is false if there is no more element.
+func (p *Peekable[T]) Next() (v T, ok bool) {
+	v, _, ok = p.p.Next()
+	return v, ok
+}
+
+// Peek returns the next element without advancing, ok is false if there is no more element.
+func (p *Peekable[T]) Peek() (v T, ok bool) {
+	v, _, ok = p.p.Peek()
+	return v, ok
+}
+
+// PeekN returns the next n elements without advancing, it returns less than n elements if there are not enough.
+func (p *Peekable[T]) PeekN(n int) []T {
+	pairs := p.p.PeekN(n)
+	out := make([]T, 0, len(pairs))
+	for _, pair := range pairs {
+		out = append(out, pair.T1)
+	}
+	return out
+}
+
+// NextIf returns the next element and advances only if f returns true for it.
+// ok is false if there is no more element or f returns false.
+func (p *Peekable[T]) NextIf(f func(T) bool) (v T, ok bool) {
+	v, _, ok = p.p.NextIf(func(v T, _ struct{}) bool {
+		return f(v)
+	})
+	return v, ok
+}
+
+// Unread pushes v back, so it is the next element returned by Next or Peek.
+// Unread can be called more than once, the elements are returned in the reverse order of pushing.
+func (p *Peekable[T]) Unread(v T) {
+	p.p.Unread(v, struct{}{})
+}
+
+// Seq returns a Seq over the remaining elements, iterating it advances p.
+// If the iteration stops early, the elements after the last yielded one are still available from p.
+func (p *Peekable[T]) Seq() Seq[T] {
+	return Seq2KeyToSeq(p.p.Seq2())
+}
+
+// Stop releases the underlying Seq, then Next returns no more element. It is safe to call Stop more than once.
+func (p *Peekable[T]) Stop() {
+	p.p.Stop()
+}
+
+// Peekable2 is like Peekable but run with Seq2.
+type Peekable2[K, V any] struct {
+	next    func() (K, V, bool)
+	stop    func()
+	buf     []union.U2[K, V]
+	stopped bool
+}
+
+// NewPeekable2 returns a Peekable2 over seq.
+func NewPeekable2[K, V any](seq Seq2[K, V]) *Peekable2[K, V] {
+	next, stop := Pull2(seq)
+	return &Peekable2[K, V]{next: next, stop: stop}
+}
+
+// fill pulls elements into the buffer until it has n elements, it returns false if there are not enough.
+func (p *Peekable2[K, V]) fill(n int) bool {
+	for len(p.buf) < n {
+		if p.stopped {
+			return false
+		}
+		k, v, ok := p.next()
+		if !ok {
+			// release the exhausted Seq2 early, but keep the buffered pairs.
+			p.stopped = true
+			p.stop()
+			return false
+		}
+		p.buf = append(p.buf, union.U2[K, V]{T1: k, T2: v})
+	}
+	return true
+}
+
+// Next returns the next key-value pair and advances, ok is false if there is no more pair.
+func (p *Peekable2[K, V]) Next() (k K, v V, ok bool) {
+	if !p.fill(1) {
+		return k, v, false
+	}
+	head := p.buf[0]
+	p.buf[0] = union.U2[K, V]{}
+	p.buf = p.buf[1:]
+	return head.T1, head.T2, true
+}
+
+// Peek returns the next key-value pair without advancing, ok is false if there is no more pair.
+func (p *Peekable2[K, V]) Peek() (k K, v V, ok bool) {
+	if !p.fill(1) {
+		return k, v, false
+	}
+	return p.buf[0].T1, p.buf[0].T2, true
+}
+
+// PeekN returns the next n key-value pairs without advancing, it returns less than n pairs if there are not enough.
+func (p *Peekable2[K, V]) PeekN(n int) []union.U2[K, V] {
+	if n <= 0 {
+		return nil
+	}
+	p.fill(n)
+	if n > len(p.buf) {
+		n = len(p.buf)
+	}
+	out := make([]union.U2[K, V], n)
+	copy(out, p.buf)
+	return out
+}
+
+// NextIf returns the next key-value pair and advances only if f returns true for it.
+// ok is false if there is no more pair or f returns false.
+func (p *Peekable2[K, V]) NextIf(f func(K, V) bool) (k K, v V, ok bool) {
+	if k, v, ok = p.Peek(); !ok || !f(k, v) {
+		var zeroK K
+		var zeroV V
+		return zeroK, zeroV, false
+	}
+	return p.Next()
+}
+
+// Unread pushes the pair k, v back, so it is the next pair returned by Next or Peek.
+func (p *Peekable2[K, V]) Unread(k K, v V) {
+	p.buf = append([]union.U2[K, V]{{T1: k, T2: v}}, p.buf...)
+}
+
+// Seq2 returns a Seq2 over the remaining key-value pairs, iterating it advances p.
+// If the iteration stops early, the pairs after the last yielded one are still available from p.
+func (p *Peekable2[K, V]) Seq2() Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for {
+			k, v, ok := p.Next()
+			if !ok || !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// Stop releases the underlying Seq2, the buffered pairs are dropped. It is safe to call Stop more than once.
+func (p *Peekable2[K, V]) Stop() {
+	p.buf = nil
+	if p.stopped {
+		return
+	}
+	p.stopped = true
+	p.stop()
+}
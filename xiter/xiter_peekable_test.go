@@ -0,0 +1,130 @@
+package xiter_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dashjay/xiter/union"
+	"github.com/dashjay/xiter/xiter"
+)
+
+func TestXIterPeekable(t *testing.T) {
+	t.Run("peekable", func(t *testing.T) {
+		p := xiter.NewPeekable(xiter.FromSlice(_range(0, 10)))
+		defer p.Stop()
+
+		v, ok := p.Peek()
+		assert.True(t, ok)
+		assert.Equal(t, 0, v)
+		v, ok = p.Next()
+		assert.True(t, ok)
+		assert.Equal(t, 0, v)
+
+		assert.Equal(t, []int{1, 2, 3}, p.PeekN(3))
+		assert.Len(t, p.PeekN(0), 0)
+		v, _ = p.Next()
+		assert.Equal(t, 1, v)
+
+		p.Unread(100)
+		p.Unread(101)
+		assert.Equal(t, []int{101, 100, 2}, p.PeekN(3))
+
+		lt := func(n int) func(int) bool {
+			return func(v int) bool { return v < n }
+		}
+		_, ok = p.NextIf(lt(100))
+		assert.False(t, ok)
+		v, ok = p.NextIf(lt(102))
+		assert.True(t, ok)
+		assert.Equal(t, 101, v)
+
+		assert.Equal(t, []int{100, 2, 3}, xiter.ToSlice(xiter.Limit(p.Seq(), 3)))
+		assert.Equal(t, _range(4, 10), p.PeekN(100))
+		assert.Equal(t, _range(4, 10), xiter.ToSlice(p.Seq()))
+
+		_, ok = p.Next()
+		assert.False(t, ok)
+		_, ok = p.Peek()
+		assert.False(t, ok)
+		_, ok = p.NextIf(lt(100))
+		assert.False(t, ok)
+		assert.Len(t, p.PeekN(1), 0)
+
+		// unread after the end
+		p.Unread(42)
+		v, ok = p.Next()
+		assert.True(t, ok)
+		assert.Equal(t, 42, v)
+	})
+
+	t.Run("peekable parse", func(t *testing.T) {
+		// group runs of digits like a tokenizer
+		p := xiter.NewPeekable(xiter.FromSlice([]rune("ab12c345")))
+		defer p.Stop()
+		isDigit := func(r rune) bool { return r >= '0' && r <= '9' }
+		var tokens []string
+		for r, ok := p.Next(); ok; r, ok = p.Next() {
+			token := []rune{r}
+			if isDigit(r) {
+				for d, ok := p.NextIf(isDigit); ok; d, ok = p.NextIf(isDigit) {
+					token = append(token, d)
+				}
+			}
+			tokens = append(tokens, string(token))
+		}
+		assert.Equal(t, []string{"a", "b", "12", "c", "345"}, tokens)
+	})
+
+	t.Run("peekable stop", func(t *testing.T) {
+		ng := stableNumGoroutine()
+		for i := 0; i < 10; i++ {
+			p := xiter.NewPeekable(xiter.Range(0, 1<<62, 1))
+			assert.Equal(t, []int{0, 1}, p.PeekN(2))
+			p.Stop()
+			p.Stop()
+			_, ok := p.Next()
+			assert.False(t, ok)
+		}
+		assert.Equal(t, ng, stableNumGoroutine())
+
+		empty := xiter.NewPeekable(xiter.FromSlice([]int{}))
+		_, ok := empty.Peek()
+		assert.False(t, ok)
+		empty.Stop()
+	})
+
+	t.Run("peekable panic", func(t *testing.T) {
+		p := xiter.NewPeekable(func(yield func(int) bool) {
+			yield(1)
+			panic("boom")
+		})
+		defer p.Stop()
+		v, _ := p.Next()
+		assert.Equal(t, 1, v)
+		assert.PanicsWithValue(t, "boom", func() { p.Next() })
+	})
+
+	t.Run("peekable2", func(t *testing.T) {
+		p := xiter.NewPeekable2(xiter.FromSliceIdx([]string{"a", "b", "c"}))
+		defer p.Stop()
+
+		k, v, ok := p.Peek()
+		assert.True(t, ok)
+		assert.Equal(t, 0, k)
+		assert.Equal(t, "a", v)
+		assert.Equal(t, []union.U2[int, string]{{T1: 0, T2: "a"}, {T1: 1, T2: "b"}}, p.PeekN(2))
+
+		_, _, ok = p.NextIf(func(k int, _ string) bool { return k > 0 })
+		assert.False(t, ok)
+		k, v, ok = p.NextIf(func(k int, _ string) bool { return k == 0 })
+		assert.True(t, ok)
+		assert.Equal(t, 0, k)
+		assert.Equal(t, "a", v)
+
+		p.Unread(9, "z")
+		assert.Equal(t, []int{9, 1, 2}, xiter.ToSliceSeq2Key(p.Seq2()))
+		_, _, ok = p.Next()
+		assert.False(t, ok)
+	})
+}
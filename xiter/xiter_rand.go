@@ -0,0 +1,36 @@
+package xiter
+
+import "github.com/dashjay/xiter/internal/xrand"
+
+// Rand is a source of uniformly distributed random uint64 values used by the random helpers,
+// such as Sample, SampleWeighted, SampleBernoulli, FromSliceShuffle and their xslice versions.
+//
+// *math/rand.Rand implements it, and on go1.22+ so do *math/rand/v2.Rand and the math/rand/v2 generators
+// like *rand.PCG and *rand.ChaCha8.
+type Rand = xrand.Rand
+
+// RandOption configures the source of randomness of random helpers.
+type RandOption func(*xrand.Options)
+
+// WithRand makes random helpers use r instead of the global source of math/rand,
+// so a seeded r gives reproducible results. By default, the global source of math/rand is used.
+// r is usually not safe for concurrent use, so a seq using it should not be iterated concurrently.
+//
+// EXAMPLE:
+//
+//	r := rand.New(rand.NewSource(42))
+//	xiter.ToSlice(xiter.FromSliceShuffle([]int{1, 2, 3}, xiter.WithRand(r))) 👉 same order for every run
+//	xslice.Shuffle([]int{1, 2, 3}, xiter.WithRand(rand.New(rand.NewSource(42)))) 👉 same order for every run
+func WithRand(r Rand) RandOption {
+	return func(o *xrand.Options) {
+		o.Rand = r
+	}
+}
+
+func newRandOptions(opts []RandOption) *xrand.Options {
+	o := &xrand.Options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
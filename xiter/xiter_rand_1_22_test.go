@@ -0,0 +1,25 @@
+//go:build go1.22
+// +build go1.22
+
+package xiter_test
+
+import (
+	randv2 "math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dashjay/xiter/xiter"
+)
+
+func TestXIterRandV2(t *testing.T) {
+	pcg := func() xiter.RandOption {
+		return xiter.WithRand(randv2.NewPCG(1, 2))
+	}
+	assert.Equal(t,
+		xiter.ToSlice(xiter.FromSliceShuffle(_range(0, 100), pcg())),
+		xiter.ToSlice(xiter.FromSliceShuffle(_range(0, 100), pcg())))
+	assert.Equal(t,
+		xiter.Sample(xiter.Range(0, 1000, 1), 5, xiter.WithRand(randv2.New(randv2.NewChaCha8([32]byte{1})))),
+		xiter.Sample(xiter.Range(0, 1000, 1), 5, xiter.WithRand(randv2.New(randv2.NewChaCha8([32]byte{1})))))
+}
@@ -0,0 +1,118 @@
+package xiter
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+)
+
+// Sample returns n elements chosen uniformly at random from seq with reservoir sampling,
+// seq is iterated once and only n elements are held in memory, so seq can be larger than memory.
+// All elements are returned if seq has no more than n elements. The order of the returned elements is not specified.
+//
+// EXAMPLE:
+//
+//	xiter.Sample(xiter.Range(0, 1000000, 1), 3) 👉 [412 98113 5077] (random)
+//	xiter.Sample(xiter.Range(0, 100, 1), 3, xiter.WithRand(rand.New(rand.NewSource(1)))) 👉 (same result for the same seed)
+func Sample[T any](seq Seq[T], n int, opts ...RandOption) []T {
+	if n <= 0 {
+		return nil
+	}
+	o := newRandOptions(opts)
+	var reservoir []T
+	var seen int64
+	seq(func(v T) bool {
+		seen++
+		if len(reservoir) < n {
+			reservoir = append(reservoir, v)
+		} else if j := o.Int63n(seen); j < int64(n) {
+			reservoir[j] = v
+		}
+		return true
+	})
+	return reservoir
+}
+
+// SampleWeighted returns n elements chosen at random from seq, the probability of each element is proportional
+// to its weight evaluated by f. It uses the A-Res algorithm of Efraimidis and Spirakis, which iterates seq once
+// and only holds n elements in memory. Elements with non-positive weights are never chosen.
+// The returned elements are ordered by their random keys, heavy elements tend to come first.
+//
+// EXAMPLE:
+//
+//	type server struct {
+//		name   string
+//		weight float64
+//	}
+//	servers := xiter.FromSlice([]server{{"a", 1}, {"b", 10}, {"c", 100}})
+//	xiter.SampleWeighted(servers, 1, func(s server) float64 { return s.weight }) 👉 [{c 100}] (most likely)
+func SampleWeighted[T any](seq Seq[T], n int, f func(T) float64, opts ...RandOption) []T {
+	if n <= 0 {
+		return nil
+	}
+	o := newRandOptions(opts)
+	h := &weightedHeap[T]{}
+	seq(func(v T) bool {
+		w := f(v)
+		if !(w > 0) {
+			return true
+		}
+		// key = u^(1/w), compared in log space to keep precision for large weights.
+		key := math.Log(o.Float64()) / w
+		if h.Len() < n {
+			heap.Push(h, weightedItem[T]{v: v, key: key})
+		} else if key > h.items[0].key {
+			h.items[0] = weightedItem[T]{v: v, key: key}
+			heap.Fix(h, 0)
+		}
+		return true
+	})
+	out := make([]T, h.Len())
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = heap.Pop(h).(weightedItem[T]).v
+	}
+	return out
+}
+
+type weightedItem[T any] struct {
+	v   T
+	key float64
+}
+
+// weightedHeap is a min-heap of items by their keys.
+type weightedHeap[T any] struct {
+	items []weightedItem[T]
+}
+
+func (h *weightedHeap[T]) Len() int           { return len(h.items) }
+func (h *weightedHeap[T]) Less(i, j int) bool { return h.items[i].key < h.items[j].key }
+func (h *weightedHeap[T]) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *weightedHeap[T]) Push(x any)         { h.items = append(h.items, x.(weightedItem[T])) }
+func (h *weightedHeap[T]) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
+
+// SampleBernoulli returns a Seq that yields each element of seq independently with probability p.
+// Unlike Sample, it is lazy and holds nothing in memory, but the number of yielded elements is random.
+// It panics if p is not in [0, 1].
+//
+// EXAMPLE:
+//
+//	seq := xiter.SampleBernoulli(xiter.Range(0, 1000, 1), 0.1)
+//	xiter.Count(seq) 👉 about 100
+func SampleBernoulli[T any](seq Seq[T], p float64, opts ...RandOption) Seq[T] {
+	if !(p >= 0 && p <= 1) {
+		panic(fmt.Sprintf("probability %v out of range [0, 1]", p))
+	}
+	o := newRandOptions(opts)
+	return func(yield func(T) bool) {
+		seq(func(v T) bool {
+			if o.Float64() < p {
+				return yield(v)
+			}
+			return true
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package xiter_test
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dashjay/xiter/xiter"
+)
+
+func TestXIterSample(t *testing.T) {
+	seeded := func() xiter.RandOption {
+		return xiter.WithRand(rand.New(rand.NewSource(42)))
+	}
+
+	t.Run("sample", func(t *testing.T) {
+		res := xiter.Sample(xiter.Range(0, 1000, 1), 10)
+		assert.Len(t, res, 10)
+		sort.Ints(res)
+		for i := 1; i < len(res); i++ {
+			assert.NotEqual(t, res[i-1], res[i])
+		}
+
+		small := xiter.Sample(xiter.FromSlice([]int{1, 2, 3}), 10)
+		assert.Equal(t, []int{1, 2, 3}, small)
+		assert.Len(t, xiter.Sample(xiter.FromSlice([]int{1, 2, 3}), 0), 0)
+		assert.Len(t, xiter.Sample(xiter.FromSlice([]int{}), 3), 0)
+		// the reservoir is not allocated for n up front
+		assert.Equal(t, []int{1, 2, 3}, xiter.Sample(xiter.FromSlice([]int{1, 2, 3}), math.MaxInt))
+
+		assert.Equal(t,
+			xiter.Sample(xiter.Range(0, 1000, 1), 5, seeded()),
+			xiter.Sample(xiter.Range(0, 1000, 1), 5, seeded()))
+	})
+
+	t.Run("sample uniform", func(t *testing.T) {
+		r := rand.New(rand.NewSource(1))
+		counts := make([]int, 10)
+		for i := 0; i < 10000; i++ {
+			for _, v := range xiter.Sample(xiter.Range(0, 10, 1), 2, xiter.WithRand(r)) {
+				counts[v]++
+			}
+		}
+		for _, c := range counts {
+			assert.InDelta(t, 2000, c, 200)
+		}
+	})
+
+	t.Run("sample weighted", func(t *testing.T) {
+		weight := func(v int) float64 { return float64(v) }
+		r := rand.New(rand.NewSource(1))
+		counts := make([]int, 4)
+		for i := 0; i < 10000; i++ {
+			res := xiter.SampleWeighted(xiter.FromSlice([]int{0, 1, 2, 3}), 1, weight, xiter.WithRand(r))
+			assert.Len(t, res, 1)
+			counts[res[0]]++
+		}
+		assert.Equal(t, 0, counts[0])
+		assert.InDelta(t, 10000.0/6, counts[1], 300)
+		assert.InDelta(t, 10000.0*2/6, counts[2], 300)
+		assert.InDelta(t, 10000.0*3/6, counts[3], 300)
+
+		res := xiter.SampleWeighted(xiter.FromSlice([]int{0, 1, 2, 3}), 10, weight)
+		sort.Ints(res)
+		assert.Equal(t, []int{1, 2, 3}, res)
+		assert.Len(t, xiter.SampleWeighted(xiter.FromSlice([]int{1}), 0, weight), 0)
+
+		assert.Equal(t,
+			xiter.SampleWeighted(xiter.Range(0, 1000, 1), 5, weight, seeded()),
+			xiter.SampleWeighted(xiter.Range(0, 1000, 1), 5, weight, seeded()))
+	})
+
+	t.Run("sample bernoulli", func(t *testing.T) {
+		seq := xiter.SampleBernoulli(xiter.Range(0, 10000, 1), 0.1)
+		assert.InDelta(t, 1000, xiter.Count(seq), 150)
+		assert.True(t, sort.IntsAreSorted(xiter.ToSlice(seq)))
+		testLimit(t, seq, 1)
+
+		assert.Equal(t, _range(0, 10), xiter.ToSlice(xiter.SampleBernoulli(xiter.Range(0, 10, 1), 1)))
+		assert.Len(t, xiter.ToSlice(xiter.SampleBernoulli(xiter.Range(0, 10, 1), 0)), 0)
+		assert.Panics(t, func() { xiter.SampleBernoulli(xiter.Range(0, 10, 1), 1.1) })
+
+		assert.Equal(t,
+			xiter.ToSlice(xiter.SampleBernoulli(xiter.Range(0, 100, 1), 0.5, seeded())),
+			xiter.ToSlice(xiter.SampleBernoulli(xiter.Range(0, 100, 1), 0.5, seeded())))
+
+		// lazy over an infinite seq
+		assert.Len(t, xiter.ToSlice(xiter.Limit(xiter.SampleBernoulli(xiter.Range(0, 1<<62, 1), 0.5), 10)), 10)
+	})
+}
@@ -0,0 +1,316 @@
+package xiter
+
+import (
+	"bufio"
+	"encoding/gob"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/dashjay/xiter/xcmp"
+)
+
+// Sort returns a seq that yields the elements of seq in ascending order.
+// Sort is equivalent to calling SortFunc with xcmp.Compare[T].
+//
+// EXAMPLE:
+//
+//	xiter.Sort(xiter.FromSlice([]int{3, 1, 2})) 👉 [1 2 3]
+func Sort[T xcmp.Ordered](seq Seq[T]) Seq[T] {
+	return SortFunc(seq, xcmp.Compare[T])
+}
+
+// SortFunc returns a seq that yields the elements of seq in the order defined by f,
+// the sort is stable, so equal elements keep their order in seq.
+//
+// All elements of seq are collected in memory when the returned seq is iterated,
+// use ExternalSortFunc when seq does not fit in memory.
+//
+// EXAMPLE:
+//
+//	seq := xiter.FromSlice([]string{"bb", "a", "ccc"})
+//	xiter.SortFunc(seq, func(a, b string) int { return len(b) - len(a) }) 👉 [ccc bb a]
+func SortFunc[T any](seq Seq[T], f func(T, T) int) Seq[T] {
+	return func(yield func(T) bool) {
+		s := ToSlice(seq)
+		sortStableFunc(s, f)
+		for _, v := range s {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// SortEncoder writes values to a sorted run spilled by ExternalSortFunc, *gob.Encoder and *json.Encoder implement it.
+type SortEncoder interface {
+	Encode(v any) error
+}
+
+// SortDecoder reads values from a sorted run spilled by ExternalSortFunc, *gob.Decoder and *json.Decoder implement it.
+type SortDecoder interface {
+	Decode(v any) error
+}
+
+// SortOption configures how ExternalSort and ExternalSortFunc spill to disk.
+type SortOption func(*sortOptions)
+
+type sortOptions struct {
+	maxInMemory int
+	maxFanIn    int
+	dir         string
+	newEncoder  func(io.Writer) SortEncoder
+	newDecoder  func(io.Reader) SortDecoder
+}
+
+const (
+	// defaultMaxInMemory is the default number of elements held in memory by ExternalSortFunc.
+	defaultMaxInMemory = 1 << 16
+	// defaultMaxFanIn is the default number of runs merged at a time by ExternalSortFunc.
+	defaultMaxFanIn = 128
+)
+
+// WithMaxInMemory sets the max number of elements held in memory, which is the size of every spilled run,
+// default is 65536. Non-positive n is ignored.
+func WithMaxInMemory(n int) SortOption {
+	return func(o *sortOptions) {
+		if n > 0 {
+			o.maxInMemory = n
+		}
+	}
+}
+
+// WithMaxFanIn sets the max number of runs merged at a time, which bounds the files open at once, default is 128.
+// If there are more runs, they are merged into fewer longer runs in several passes first. n less than 2 is ignored.
+func WithMaxFanIn(n int) SortOption {
+	return func(o *sortOptions) {
+		if n >= 2 {
+			o.maxFanIn = n
+		}
+	}
+}
+
+// WithTempDir sets the directory where runs are spilled, default is os.TempDir().
+func WithTempDir(dir string) SortOption {
+	return func(o *sortOptions) {
+		o.dir = dir
+	}
+}
+
+// WithCodec sets how elements are encoded to and decoded from the spilled runs, default is encoding/gob.
+//
+// EXAMPLE:
+//
+//	xiter.WithCodec(
+//		func(w io.Writer) xiter.SortEncoder { return json.NewEncoder(w) },
+//		func(r io.Reader) xiter.SortDecoder { return json.NewDecoder(r) },
+//	)
+func WithCodec(newEncoder func(io.Writer) SortEncoder, newDecoder func(io.Reader) SortDecoder) SortOption {
+	return func(o *sortOptions) {
+		o.newEncoder = newEncoder
+		o.newDecoder = newDecoder
+	}
+}
+
+func newSortOptions(opts []SortOption) *sortOptions {
+	o := &sortOptions{
+		maxInMemory: defaultMaxInMemory,
+		maxFanIn:    defaultMaxFanIn,
+		newEncoder: func(w io.Writer) SortEncoder {
+			return gob.NewEncoder(w)
+		},
+		newDecoder: func(r io.Reader) SortDecoder {
+			return gob.NewDecoder(r)
+		},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// ExternalSort returns a SeqErr that yields the elements of seq in ascending order, spilling to disk if needed.
+// ExternalSort is equivalent to calling ExternalSortFunc with xcmp.Compare[T].
+func ExternalSort[T xcmp.Ordered](seq Seq[T], opts ...SortOption) SeqErr[T] {
+	return ExternalSortFunc(seq, xcmp.Compare[T], opts...)
+}
+
+// ExternalSortFunc returns a SeqErr that yields the elements of seq in the stable order defined by f,
+// it can sort sequences larger than memory.
+//
+// Elements are collected in memory up to the limit set by WithMaxInMemory, every time the limit is reached
+// they are sorted and spilled to a temp file as a run, then all the runs are merged back like MergeNFunc.
+// At most the number of runs set by WithMaxFanIn are open at a time, more runs are merged in several passes.
+// If seq fits in memory, nothing is written to disk.
+//
+// Elements must be supported by the codec (encoding/gob by default, so only exported fields of structs are kept).
+// If creating, writing or reading a run fails, the error is yielded with a zero value and the iteration stops.
+// The temp files are removed when the iteration ends, even if the consumer stops early.
+//
+// EXAMPLE:
+//
+//	seq := xiter.ExternalSortFunc(bigSeq, xcmp.Compare[int], xiter.WithMaxInMemory(1<<20))
+//	sorted, err := xiter.TryToSlice(seq)
+func ExternalSortFunc[T any](seq Seq[T], f func(T, T) int, opts ...SortOption) SeqErr[T] {
+	o := newSortOptions(opts)
+	return func(yield func(T, error) bool) {
+		var zero T
+		var runs []*sortRun[T]
+		defer func() {
+			for _, run := range runs {
+				run.remove()
+			}
+		}()
+
+		var buf []T
+		var err error
+		seq(func(v T) bool {
+			buf = append(buf, v)
+			if len(buf) < o.maxInMemory {
+				return true
+			}
+			sortStableFunc(buf, f)
+			var run *sortRun[T]
+			run, err = spillSortRun(o, FromSlice(buf))
+			if err != nil {
+				return false
+			}
+			runs = append(runs, run)
+			buf = buf[:0]
+			return true
+		})
+		if err == nil {
+			// the elements left in memory take one more input of the final merge.
+			runs, err = mergeSortRuns(o, f, runs)
+		}
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		sortStableFunc(buf, f)
+
+		// the elements left in memory are the latest run, so they are merged last to keep the sort stable.
+		seqs := make([]Seq[T], 0, len(runs)+1)
+		for _, run := range runs {
+			seqs = append(seqs, run.seq(o, &err))
+		}
+		seqs = append(seqs, FromSlice(buf))
+		MergeNFunc(f, seqs...)(func(v T) bool {
+			if err != nil {
+				return false
+			}
+			return yield(v, nil)
+		})
+		if err != nil {
+			yield(zero, err)
+		}
+	}
+}
+
+// mergeSortRuns merges every maxFanIn consecutive runs into one until there are less than maxFanIn runs.
+// Consecutive runs are merged in order, so the sort is still stable. The runs are removed once merged,
+// and on error the runs left are returned to be removed by the caller.
+func mergeSortRuns[T any](o *sortOptions, f func(T, T) int, runs []*sortRun[T]) ([]*sortRun[T], error) {
+	for len(runs) >= o.maxFanIn {
+		merged := make([]*sortRun[T], 0, (len(runs)+o.maxFanIn-1)/o.maxFanIn)
+		for i := 0; i < len(runs); i += o.maxFanIn {
+			group := runs[i:]
+			if len(group) > o.maxFanIn {
+				group = group[:o.maxFanIn]
+			}
+			if len(group) == 1 {
+				merged = append(merged, group[0])
+				continue
+			}
+			var readErr error
+			seqs := make([]Seq[T], 0, len(group))
+			for _, run := range group {
+				seqs = append(seqs, run.seq(o, &readErr))
+			}
+			run, err := spillSortRun(o, MergeNFunc(f, seqs...))
+			if err == nil && readErr != nil {
+				run.remove()
+				err = readErr
+			}
+			if err != nil {
+				return append(merged, runs[i:]...), err
+			}
+			for _, r := range group {
+				r.remove()
+			}
+			merged = append(merged, run)
+		}
+		runs = merged
+	}
+	return runs, nil
+}
+
+// sortRun is a sorted run spilled to a temp file, the file is only open while it is written or read.
+type sortRun[T any] struct {
+	name string
+	n    int
+}
+
+// spillSortRun writes the elements of seq to a new temp file.
+func spillSortRun[T any](o *sortOptions, seq Seq[T]) (*sortRun[T], error) {
+	f, err := os.CreateTemp(o.dir, "xiter-sort-*")
+	if err != nil {
+		return nil, err
+	}
+	run := &sortRun[T]{name: f.Name()}
+	w := bufio.NewWriter(f)
+	enc := o.newEncoder(w)
+	seq(func(v T) bool {
+		if err = enc.Encode(v); err != nil {
+			return false
+		}
+		run.n++
+		return true
+	})
+	if err == nil {
+		err = w.Flush()
+	}
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		run.remove()
+		return nil, err
+	}
+	return run, nil
+}
+
+// seq reads the run from the beginning, it stops and sets *err if reading fails.
+func (r *sortRun[T]) seq(o *sortOptions, err *error) Seq[T] {
+	return func(yield func(T) bool) {
+		f, e := os.Open(r.name)
+		if e != nil {
+			*err = e
+			return
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		dec := o.newDecoder(bufio.NewReader(f))
+		for i := 0; i < r.n; i++ {
+			var v T
+			if e = dec.Decode(&v); e != nil {
+				*err = e
+				return
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+func (r *sortRun[T]) remove() {
+	_ = os.Remove(r.name)
+}
+
+func sortStableFunc[T any](s []T, f func(T, T) int) {
+	sort.SliceStable(s, func(i, j int) bool {
+		return f(s[i], s[j]) < 0
+	})
+}
@@ -0,0 +1,199 @@
+package xiter_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dashjay/xiter/xiter"
+)
+
+type sortItem struct {
+	Key   int
+	Order int
+}
+
+func TestXIterSort(t *testing.T) {
+	shuffled := func(n int) []int {
+		s := _range(0, n)
+		r := rand.New(rand.NewSource(1))
+		r.Shuffle(len(s), func(i, j int) { s[i], s[j] = s[j], s[i] })
+		return s
+	}
+	byKey := func(a, b sortItem) int {
+		return a.Key - b.Key
+	}
+	items := func(n int) []sortItem {
+		s := make([]sortItem, 0, n)
+		for i := 0; i < n; i++ {
+			s = append(s, sortItem{Key: (i * 7) % 10, Order: i})
+		}
+		return s
+	}
+	assertStable := func(t *testing.T, s []sortItem) {
+		assert.True(t, sort.SliceIsSorted(s, func(i, j int) bool {
+			if s[i].Key != s[j].Key {
+				return s[i].Key < s[j].Key
+			}
+			return s[i].Order < s[j].Order
+		}))
+	}
+	assertEmptyDir := func(t *testing.T, dir string) {
+		entries, err := os.ReadDir(dir)
+		assert.Nil(t, err)
+		assert.Len(t, entries, 0)
+	}
+
+	t.Run("sort", func(t *testing.T) {
+		seq := xiter.Sort(xiter.FromSlice(shuffled(100)))
+		assert.Equal(t, _range(0, 100), xiter.ToSlice(seq))
+		assert.Equal(t, _range(0, 100), xiter.ToSlice(seq))
+		testLimit(t, seq, 1)
+		assert.Len(t, xiter.ToSlice(xiter.Sort(xiter.FromSlice([]int{}))), 0)
+
+		res := xiter.ToSlice(xiter.SortFunc(xiter.FromSlice(items(100)), byKey))
+		assert.Len(t, res, 100)
+		assertStable(t, res)
+	})
+
+	t.Run("external sort in memory", func(t *testing.T) {
+		dir := t.TempDir()
+		res, err := xiter.TryToSlice(xiter.ExternalSort(xiter.FromSlice(shuffled(100)), xiter.WithTempDir(dir)))
+		assert.Nil(t, err)
+		assert.Equal(t, _range(0, 100), res)
+		assertEmptyDir(t, dir)
+	})
+
+	t.Run("external sort spill", func(t *testing.T) {
+		dir := t.TempDir()
+		seq := xiter.ExternalSort(xiter.FromSlice(shuffled(1000)), xiter.WithTempDir(dir), xiter.WithMaxInMemory(64))
+		res, err := xiter.TryToSlice(seq)
+		assert.Nil(t, err)
+		assert.Equal(t, _range(0, 1000), res)
+		assertEmptyDir(t, dir)
+
+		sorted, err := xiter.TryToSlice(xiter.ExternalSortFunc(xiter.FromSlice(items(1000)), byKey,
+			xiter.WithTempDir(dir), xiter.WithMaxInMemory(33)))
+		assert.Nil(t, err)
+		assert.Len(t, sorted, 1000)
+		assertStable(t, sorted)
+	})
+
+	t.Run("external sort fan in", func(t *testing.T) {
+		dir := t.TempDir()
+		for _, fanIn := range []int{2, 3, 4} {
+			sorted, err := xiter.TryToSlice(xiter.ExternalSortFunc(xiter.FromSlice(items(1000)), byKey,
+				xiter.WithTempDir(dir), xiter.WithMaxInMemory(10), xiter.WithMaxFanIn(fanIn)))
+			assert.Nil(t, err)
+			assert.Len(t, sorted, 1000)
+			assertStable(t, sorted)
+			assertEmptyDir(t, dir)
+		}
+
+		// the runs are merged down to 3 files before the final merge with the elements in memory
+		seq := xiter.ExternalSort(xiter.FromSlice(shuffled(1005)), xiter.WithTempDir(dir),
+			xiter.WithMaxInMemory(100), xiter.WithMaxFanIn(4))
+		var res []int
+		seq(func(v int, err error) bool {
+			if len(res) == 0 {
+				entries, _ := os.ReadDir(dir)
+				assert.Len(t, entries, 3)
+			}
+			assert.Nil(t, err)
+			res = append(res, v)
+			return true
+		})
+		assert.Equal(t, _range(0, 1005), res)
+		assertEmptyDir(t, dir)
+	})
+
+	t.Run("external sort early stop", func(t *testing.T) {
+		dir := t.TempDir()
+		seq := xiter.ExternalSort(xiter.FromSlice(shuffled(1000)), xiter.WithTempDir(dir), xiter.WithMaxInMemory(100))
+		var res []int
+		seq(func(v int, err error) bool {
+			assert.Nil(t, err)
+			res = append(res, v)
+			if len(res) == 3 {
+				entries, _ := os.ReadDir(dir)
+				assert.Len(t, entries, 10)
+				return false
+			}
+			return true
+		})
+		assert.Equal(t, []int{0, 1, 2}, res)
+		assertEmptyDir(t, dir)
+
+		assert.Panics(t, func() {
+			xiter.ExternalSort(func(yield func(int) bool) {
+				for i := 0; i < 10; i++ {
+					yield(i)
+				}
+				panic("boom")
+			}, xiter.WithTempDir(dir), xiter.WithMaxInMemory(2))(func(int, error) bool { return true })
+		})
+		assertEmptyDir(t, dir)
+	})
+
+	t.Run("external sort codec", func(t *testing.T) {
+		dir := t.TempDir()
+		jsonCodec := xiter.WithCodec(
+			func(w io.Writer) xiter.SortEncoder { return json.NewEncoder(w) },
+			func(r io.Reader) xiter.SortDecoder { return json.NewDecoder(r) },
+		)
+		res, err := xiter.TryToSlice(xiter.ExternalSort(xiter.FromSlice([]string{"c", "a", "d", "b"}),
+			xiter.WithTempDir(dir), xiter.WithMaxInMemory(1), jsonCodec))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a", "b", "c", "d"}, res)
+		assertEmptyDir(t, dir)
+	})
+
+	t.Run("external sort error", func(t *testing.T) {
+		errBad := errors.New("bad")
+		dir := t.TempDir()
+		failEncode := xiter.WithCodec(
+			func(w io.Writer) xiter.SortEncoder { return failCodec{errBad} },
+			func(r io.Reader) xiter.SortDecoder { return failCodec{errBad} },
+		)
+		res, err := xiter.TryToSlice(xiter.ExternalSort(xiter.FromSlice(shuffled(10)),
+			xiter.WithTempDir(dir), xiter.WithMaxInMemory(2), failEncode))
+		assert.Equal(t, errBad, err)
+		assert.Len(t, res, 0)
+		assertEmptyDir(t, dir)
+
+		_, err = xiter.TryToSlice(xiter.ExternalSort(xiter.FromSlice(shuffled(10)),
+			xiter.WithTempDir(dir+"/not-exist"), xiter.WithMaxInMemory(2)))
+		assert.NotNil(t, err)
+
+		failDecode := xiter.WithCodec(
+			func(w io.Writer) xiter.SortEncoder { return json.NewEncoder(w) },
+			func(r io.Reader) xiter.SortDecoder { return failCodec{errBad} },
+		)
+		res, err = xiter.TryToSlice(xiter.ExternalSort(xiter.FromSlice(shuffled(10)),
+			xiter.WithTempDir(dir), xiter.WithMaxInMemory(2), failDecode))
+		assert.Equal(t, errBad, err)
+		assert.Len(t, res, 0)
+		assertEmptyDir(t, dir)
+
+		// a run fails to be read in a merge pass before the final merge
+		res, err = xiter.TryToSlice(xiter.ExternalSort(xiter.FromSlice(shuffled(10)),
+			xiter.WithTempDir(dir), xiter.WithMaxInMemory(2), xiter.WithMaxFanIn(2), failDecode))
+		assert.Equal(t, errBad, err)
+		assert.Len(t, res, 0)
+		assertEmptyDir(t, dir)
+	})
+}
+
+type failCodec struct {
+	err error
+}
+
+func (f failCodec) Encode(any) error { return f.err }
+
+func (f failCodec) Decode(any) error { return f.err }
@@ -0,0 +1,171 @@
+package xiter
+
+import "github.com/dashjay/xiter/xcmp"
+
+// The Sorted* functions below run set operations on sequences which are already sorted in ascending order,
+// by walking both sequences at the same time instead of building maps like Intersect, Union and Difference.
+// They use O(1) memory and work with any type through a comparison function.
+//
+// Duplicated elements are handled like multisets: each element of left can match only one equal element of right.
+// If the input sequences are not sorted, the output is unspecified.
+
+// SortedIntersect returns a seq that only contains elements in both sorted left and right.
+// SortedIntersect is equivalent to calling SortedIntersectFunc with xcmp.Compare[T].
+//
+// EXAMPLE:
+//
+//	left := xiter.FromSlice([]int{1, 2, 3, 4})
+//	right := xiter.FromSlice([]int{3, 4, 5, 6})
+//	xiter.SortedIntersect(left, right) 👉 [3 4]
+func SortedIntersect[T xcmp.Ordered](left, right Seq[T]) Seq[T] {
+	return SortedIntersectFunc(left, right, xcmp.Compare[T])
+}
+
+// SortedIntersectFunc returns a seq that only contains elements in both left and right sorted by f.
+// When elements are equal, the one from left is yielded.
+func SortedIntersectFunc[T any](left, right Seq[T], f func(T, T) int) Seq[T] {
+	return Seq2KeyToSeq(SortedIntersectFunc2(seqToKeySeq2(left), seqToKeySeq2(right), f))
+}
+
+// SortedUnion returns a seq that contains all elements in sorted left and right, equal elements appear only once.
+// SortedUnion is equivalent to calling SortedUnionFunc with xcmp.Compare[T].
+//
+// EXAMPLE:
+//
+//	left := xiter.FromSlice([]int{1, 2, 3, 4})
+//	right := xiter.FromSlice([]int{3, 4, 5, 6})
+//	xiter.SortedUnion(left, right) 👉 [1 2 3 4 5 6]
+func SortedUnion[T xcmp.Ordered](left, right Seq[T]) Seq[T] {
+	return SortedUnionFunc(left, right, xcmp.Compare[T])
+}
+
+// SortedUnionFunc returns a seq that contains all elements in left and right sorted by f.
+// When elements are equal, only the one from left is yielded.
+func SortedUnionFunc[T any](left, right Seq[T], f func(T, T) int) Seq[T] {
+	return Seq2KeyToSeq(SortedUnionFunc2(seqToKeySeq2(left), seqToKeySeq2(right), f))
+}
+
+// SortedDifference returns a seq that contains elements in sorted left but not in sorted right.
+// SortedDifference is equivalent to calling SortedDifferenceFunc with xcmp.Compare[T].
+//
+// EXAMPLE:
+//
+//	left := xiter.FromSlice([]int{1, 2, 3, 4})
+//	right := xiter.FromSlice([]int{3, 4, 5, 6})
+//	xiter.SortedDifference(left, right) 👉 [1 2]
+func SortedDifference[T xcmp.Ordered](left, right Seq[T]) Seq[T] {
+	return SortedDifferenceFunc(left, right, xcmp.Compare[T])
+}
+
+// SortedDifferenceFunc returns a seq that contains elements in left but not in right, both sorted by f.
+func SortedDifferenceFunc[T any](left, right Seq[T], f func(T, T) int) Seq[T] {
+	return Seq2KeyToSeq(SortedDifferenceFunc2(seqToKeySeq2(left), seqToKeySeq2(right), f))
+}
+
+// SortedSymmetricDifference returns a sorted seq that contains elements in exactly one of sorted left and right.
+// SortedSymmetricDifference is equivalent to calling SortedSymmetricDifferenceFunc with xcmp.Compare[T].
+//
+// EXAMPLE:
+//
+//	left := xiter.FromSlice([]int{1, 2, 3, 4})
+//	right := xiter.FromSlice([]int{3, 4, 5, 6})
+//	xiter.SortedSymmetricDifference(left, right) 👉 [1 2 5 6]
+func SortedSymmetricDifference[T xcmp.Ordered](left, right Seq[T]) Seq[T] {
+	return SortedSymmetricDifferenceFunc(left, right, xcmp.Compare[T])
+}
+
+// SortedSymmetricDifferenceFunc returns a seq that contains elements in exactly one of left and right, both sorted by f.
+func SortedSymmetricDifferenceFunc[T any](left, right Seq[T], f func(T, T) int) Seq[T] {
+	return Seq2KeyToSeq(SortedSymmetricDifferenceFunc2(seqToKeySeq2(left), seqToKeySeq2(right), f))
+}
+
+// SortedIntersect2 returns a Seq2 that only contains pairs from left whose key is also in right.
+// Like SortedIntersect but run with Seq2 sorted by keys.
+func SortedIntersect2[K xcmp.Ordered, V any](left, right Seq2[K, V]) Seq2[K, V] {
+	return SortedIntersectFunc2(left, right, xcmp.Compare[K])
+}
+
+// SortedIntersectFunc2 is like SortedIntersectFunc but run with Seq2 sorted by keys.
+func SortedIntersectFunc2[K, V any](left, right Seq2[K, V], f func(K, K) int) Seq2[K, V] {
+	return sortedSetOp(left, right, f, false, false, true)
+}
+
+// SortedUnion2 returns a Seq2 that contains all pairs of left and right, pairs from right with a key in left are dropped.
+// Like SortedUnion but run with Seq2 sorted by keys.
+func SortedUnion2[K xcmp.Ordered, V any](left, right Seq2[K, V]) Seq2[K, V] {
+	return SortedUnionFunc2(left, right, xcmp.Compare[K])
+}
+
+// SortedUnionFunc2 is like SortedUnionFunc but run with Seq2 sorted by keys.
+func SortedUnionFunc2[K, V any](left, right Seq2[K, V], f func(K, K) int) Seq2[K, V] {
+	return sortedSetOp(left, right, f, true, true, true)
+}
+
+// SortedDifference2 returns a Seq2 that contains pairs of left whose key is not in right.
+// Like SortedDifference but run with Seq2 sorted by keys.
+func SortedDifference2[K xcmp.Ordered, V any](left, right Seq2[K, V]) Seq2[K, V] {
+	return SortedDifferenceFunc2(left, right, xcmp.Compare[K])
+}
+
+// SortedDifferenceFunc2 is like SortedDifferenceFunc but run with Seq2 sorted by keys.
+func SortedDifferenceFunc2[K, V any](left, right Seq2[K, V], f func(K, K) int) Seq2[K, V] {
+	return sortedSetOp(left, right, f, true, false, false)
+}
+
+// SortedSymmetricDifference2 returns a Seq2 that contains pairs whose key is in exactly one of left and right.
+// Like SortedSymmetricDifference but run with Seq2 sorted by keys.
+func SortedSymmetricDifference2[K xcmp.Ordered, V any](left, right Seq2[K, V]) Seq2[K, V] {
+	return SortedSymmetricDifferenceFunc2(left, right, xcmp.Compare[K])
+}
+
+// SortedSymmetricDifferenceFunc2 is like SortedSymmetricDifferenceFunc but run with Seq2 sorted by keys.
+func SortedSymmetricDifferenceFunc2[K, V any](left, right Seq2[K, V], f func(K, K) int) Seq2[K, V] {
+	return sortedSetOp(left, right, f, true, true, false)
+}
+
+// sortedSetOp walks the sorted left and right at the same time,
+// it yields the pairs only in left if onlyLeft, only in right if onlyRight,
+// and the pairs of left which have a match in right if both.
+func sortedSetOp[K, V any](left, right Seq2[K, V], f func(K, K) int, onlyLeft, onlyRight, both bool) Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		next, stop := Pull2(right)
+		defer stop()
+		rk, rv, rok := next()
+		stopped := false
+		left(func(lk K, lv V) bool {
+			for rok && f(rk, lk) < 0 {
+				if onlyRight && !yield(rk, rv) {
+					stopped = true
+					return false
+				}
+				rk, rv, rok = next()
+			}
+			if rok && f(lk, rk) == 0 {
+				rk, rv, rok = next()
+				if both && !yield(lk, lv) {
+					stopped = true
+					return false
+				}
+				return true
+			}
+			if !rok && !onlyLeft {
+				// nothing in left can be yielded once right is exhausted.
+				return false
+			}
+			if onlyLeft && !yield(lk, lv) {
+				stopped = true
+				return false
+			}
+			return true
+		})
+		if stopped || !onlyRight {
+			return
+		}
+		for rok {
+			if !yield(rk, rv) {
+				return
+			}
+			rk, rv, rok = next()
+		}
+	}
+}
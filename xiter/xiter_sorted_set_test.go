@@ -0,0 +1,73 @@
+package xiter_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dashjay/xiter/xiter"
+)
+
+func TestXIterSortedSet(t *testing.T) {
+	left := xiter.FromSlice([]int{1, 2, 3, 4})
+	right := xiter.FromSlice([]int{3, 4, 5, 6})
+	empty := xiter.FromSlice([]int{})
+
+	t.Run("sorted intersect", func(t *testing.T) {
+		assert.Equal(t, []int{3, 4}, xiter.ToSlice(xiter.SortedIntersect(left, right)))
+		assert.Equal(t, []int{3, 4}, xiter.ToSlice(xiter.SortedIntersect(right, left)))
+		assert.Len(t, xiter.ToSlice(xiter.SortedIntersect(left, empty)), 0)
+		assert.Equal(t, []int{2, 2}, xiter.ToSlice(xiter.SortedIntersect(xiter.FromSlice([]int{1, 2, 2, 2}), xiter.FromSlice([]int{2, 2, 3}))))
+		testLimit(t, xiter.SortedIntersect(left, right), 1)
+
+		// infinite left stops when right is exhausted
+		assert.Equal(t, []int{3, 4, 5, 6}, xiter.ToSlice(xiter.SortedIntersect(xiter.Range(0, 1<<62, 1), right)))
+	})
+
+	t.Run("sorted union", func(t *testing.T) {
+		assert.Equal(t, _range(1, 7), xiter.ToSlice(xiter.SortedUnion(left, right)))
+		assert.Equal(t, _range(1, 7), xiter.ToSlice(xiter.SortedUnion(right, left)))
+		assert.Equal(t, []int{1, 2, 3, 4}, xiter.ToSlice(xiter.SortedUnion(empty, left)))
+		assert.Equal(t, []int{1, 2, 2, 3}, xiter.ToSlice(xiter.SortedUnion(xiter.FromSlice([]int{1, 2}), xiter.FromSlice([]int{2, 2, 3}))))
+		testLimit(t, xiter.SortedUnion(left, right), 5)
+	})
+
+	t.Run("sorted difference", func(t *testing.T) {
+		assert.Equal(t, []int{1, 2}, xiter.ToSlice(xiter.SortedDifference(left, right)))
+		assert.Equal(t, []int{5, 6}, xiter.ToSlice(xiter.SortedDifference(right, left)))
+		assert.Equal(t, []int{1, 2, 3, 4}, xiter.ToSlice(xiter.SortedDifference(left, empty)))
+		assert.Equal(t, []int{2}, xiter.ToSlice(xiter.SortedDifference(xiter.FromSlice([]int{2, 2}), xiter.FromSlice([]int{2}))))
+	})
+
+	t.Run("sorted symmetric difference", func(t *testing.T) {
+		assert.Equal(t, []int{1, 2, 5, 6}, xiter.ToSlice(xiter.SortedSymmetricDifference(left, right)))
+		assert.Equal(t, []int{1, 2, 5, 6}, xiter.ToSlice(xiter.SortedSymmetricDifference(right, left)))
+		assert.Len(t, xiter.ToSlice(xiter.SortedSymmetricDifference(left, left)), 0)
+		testLimit(t, xiter.SortedSymmetricDifference(left, right), 3)
+	})
+
+	t.Run("sorted func", func(t *testing.T) {
+		cmpFold := func(a, b string) int {
+			return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+		}
+		l := xiter.FromSlice([]string{"A", "b", "C"})
+		r := xiter.FromSlice([]string{"a", "c", "d"})
+		assert.Equal(t, []string{"A", "C"}, xiter.ToSlice(xiter.SortedIntersectFunc(l, r, cmpFold)))
+		assert.Equal(t, []string{"A", "b", "C", "d"}, xiter.ToSlice(xiter.SortedUnionFunc(l, r, cmpFold)))
+		assert.Equal(t, []string{"b"}, xiter.ToSlice(xiter.SortedDifferenceFunc(l, r, cmpFold)))
+		assert.Equal(t, []string{"b", "d"}, xiter.ToSlice(xiter.SortedSymmetricDifferenceFunc(l, r, cmpFold)))
+	})
+
+	t.Run("sorted seq2", func(t *testing.T) {
+		l := xiter.MapToSeq2Value(left, func(v int) (int, string) { return v, "l" })
+		r := xiter.MapToSeq2Value(right, func(v int) (int, string) { return v, "r" })
+
+		assert.Equal(t, []string{"l", "l"}, xiter.ToSliceSeq2Value(xiter.SortedIntersect2(l, r)))
+		assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, xiter.ToSliceSeq2Key(xiter.SortedUnion2(l, r)))
+		assert.Equal(t, []string{"l", "l", "l", "l", "r", "r"}, xiter.ToSliceSeq2Value(xiter.SortedUnion2(l, r)))
+		assert.Equal(t, []int{1, 2}, xiter.ToSliceSeq2Key(xiter.SortedDifference2(l, r)))
+		assert.Equal(t, []string{"l", "l", "r", "r"}, xiter.ToSliceSeq2Value(xiter.SortedSymmetricDifference2(l, r)))
+		testLimit2(t, xiter.SortedSymmetricDifference2(l, r), 3)
+	})
+}
@@ -2,8 +2,10 @@ package xiter_test
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"math"
+	"math/rand"
 	"strconv"
 	"testing"
 
@@ -322,6 +324,99 @@ func TestXIter(t *testing.T) {
 		assert.Equal(t, _range(0, 0), xiter.ToSlice(xiter.Limit(xiter.FromSlice(_range(0, 10)), 0)))
 	})
 
+	t.Run("take while and drop while", func(t *testing.T) {
+		lt5 := func(v int) bool { return v < 5 }
+		seq := xiter.FromSlice([]int{1, 3, 5, 7, 2, 4})
+
+		assert.Equal(t, []int{1, 3}, xiter.ToSlice(xiter.TakeWhile(lt5, seq)))
+		assert.Len(t, xiter.ToSlice(xiter.TakeWhile(lt5, xiter.FromSlice([]int{6, 1}))), 0)
+		assert.Equal(t, []int{1, 3, 5}, xiter.ToSlice(xiter.TakeUntil(func(v int) bool { return v >= 5 }, seq)))
+		assert.Equal(t, []int{1, 3, 5, 7, 2, 4}, xiter.ToSlice(xiter.TakeUntil(func(v int) bool { return v > 10 }, seq)))
+		assert.Equal(t, []int{5, 7, 2, 4}, xiter.ToSlice(xiter.DropWhile(lt5, seq)))
+		assert.Len(t, xiter.ToSlice(xiter.DropWhile(lt5, xiter.FromSlice([]int{1, 2}))), 0)
+		assert.Equal(t, []int{5, 7, 2, 4}, xiter.ToSlice(xiter.SkipWhile(lt5, seq)))
+		testLimit(t, xiter.TakeWhile(lt5, seq), 1)
+		testLimit(t, xiter.TakeUntil(lt5, seq), 1)
+		testLimit(t, xiter.DropWhile(lt5, seq), 1)
+
+		// TakeWhile and TakeUntil stop infinite seqs
+		assert.Equal(t, _range(0, 5), xiter.ToSlice(xiter.TakeWhile(lt5, xiter.Range(0, 1<<62, 1))))
+		assert.Equal(t, _range(0, 6), xiter.ToSlice(xiter.TakeUntil(func(v int) bool { return v == 5 }, xiter.Range(0, 1<<62, 1))))
+
+		seq2 := xiter.FromSliceIdx([]int{1, 3, 5, 7, 2, 4})
+		keyLt2 := func(k int, _ int) bool { return k < 2 }
+		assert.Equal(t, []int{1, 3}, xiter.ToSliceSeq2Value(xiter.TakeWhile2(keyLt2, seq2)))
+		assert.Equal(t, []int{0, 1, 2}, xiter.ToSliceSeq2Key(xiter.TakeUntil2(func(_ int, v int) bool { return v == 5 }, seq2)))
+		assert.Equal(t, []int{2, 3, 4, 5}, xiter.ToSliceSeq2Key(xiter.DropWhile2(keyLt2, seq2)))
+		assert.Equal(t, []int{2, 3, 4, 5}, xiter.ToSliceSeq2Key(xiter.SkipWhile2(keyLt2, seq2)))
+		testLimit2(t, xiter.TakeWhile2(keyLt2, seq2), 1)
+		testLimit2(t, xiter.TakeUntil2(keyLt2, seq2), 1)
+		testLimit2(t, xiter.DropWhile2(keyLt2, seq2), 1)
+	})
+
+	t.Run("split at and span", func(t *testing.T) {
+		pulled := 0
+		source := func(yield func(int) bool) {
+			for i := 0; i < 5; i++ {
+				pulled++
+				if !yield(i) {
+					return
+				}
+			}
+		}
+
+		prefix, rest, stop := xiter.SplitAt(source, 2)
+		assert.Equal(t, []int{0, 1}, xiter.ToSlice(prefix))
+		assert.Equal(t, []int{2, 3, 4}, xiter.ToSlice(rest))
+		assert.Equal(t, 5, pulled)
+		stop()
+
+		// unconsumed prefix values are dropped by rest
+		prefix, rest, stop = xiter.SplitAt(xiter.FromSlice(_range(0, 10)), 5)
+		assert.Equal(t, []int{0, 1}, xiter.ToSlice(xiter.Limit(prefix, 2)))
+		assert.Equal(t, _range(5, 10), xiter.ToSlice(rest))
+		assert.Len(t, xiter.ToSlice(rest), 0)
+		stop()
+
+		prefix, rest, stop = xiter.SplitAt(xiter.FromSlice(_range(0, 3)), 0)
+		assert.Len(t, xiter.ToSlice(prefix), 0)
+		assert.Equal(t, _range(0, 3), xiter.ToSlice(rest))
+		stop()
+
+		prefix, rest, stop = xiter.SplitAt(xiter.FromSlice(_range(0, 3)), 10)
+		assert.Equal(t, _range(0, 3), xiter.ToSlice(prefix))
+		assert.Len(t, xiter.ToSlice(rest), 0)
+		stop()
+
+		// stop releases the source when rest is not iterated
+		ng := stableNumGoroutine()
+		prefix, _, stop = xiter.SplitAt(xiter.Range(0, 1<<62, 1), 3)
+		assert.Equal(t, _range(0, 3), xiter.ToSlice(prefix))
+		stop()
+		stop()
+		assert.Equal(t, ng, stableNumGoroutine())
+
+		prefix, rest, stop = xiter.Span(func(v int) bool { return v < 3 }, xiter.FromSlice([]int{1, 2, 3, 1}))
+		assert.Equal(t, []int{1, 2}, xiter.ToSlice(prefix))
+		assert.Equal(t, []int{3, 1}, xiter.ToSlice(rest))
+		stop()
+
+		prefix, rest, stop = xiter.Span(func(v int) bool { return v < 3 }, xiter.FromSlice([]int{1, 2, 3, 1}))
+		assert.Equal(t, []int{3}, xiter.ToSlice(xiter.Limit(rest, 1)))
+		assert.Len(t, xiter.ToSlice(prefix), 0)
+		stop()
+
+		prefix2, rest2, stop := xiter.SplitAt2(xiter.FromSliceIdx([]string{"a", "b", "c"}), 1)
+		assert.Equal(t, []string{"a"}, xiter.ToSliceSeq2Value(prefix2))
+		assert.Equal(t, []int{1, 2}, xiter.ToSliceSeq2Key(rest2))
+		stop()
+
+		prefix2, rest2, stop = xiter.Span2(func(k int, _ string) bool { return k < 2 }, xiter.FromSliceIdx([]string{"a", "b", "c"}))
+		assert.Equal(t, []string{"a", "b"}, xiter.ToSliceSeq2Value(prefix2))
+		assert.Equal(t, []string{"c"}, xiter.ToSliceSeq2Value(rest2))
+		stop()
+	})
+
 	t.Run("test repeat", func(t *testing.T) {
 		assert.Equal(t, []int{1, 2, 3, 1, 2, 3, 1, 2, 3}, xiter.ToSlice(xiter.Repeat(xiter.FromSlice([]int{1, 2, 3}), 3)))
 	})
@@ -329,6 +424,14 @@ func TestXIter(t *testing.T) {
 	t.Run("test shuffle", func(t *testing.T) {
 		assert.Len(t, xiter.ToSlice(xiter.Limit(xiter.FromSliceShuffle(_range(0, 10)), 5)), 5)
 		assert.Len(t, xiter.ToSlice(xiter.FromSliceShuffle(_range(0, 10))), 10)
+
+		seeded := func() xiter.RandOption {
+			return xiter.WithRand(rand.New(rand.NewSource(1)))
+		}
+		shuffled := xiter.ToSlice(xiter.FromSliceShuffle(_range(0, 100), seeded()))
+		assert.Equal(t, shuffled, xiter.ToSlice(xiter.FromSliceShuffle(_range(0, 100), seeded())))
+		assert.NotEqual(t, _range(0, 100), shuffled)
+		assert.ElementsMatch(t, _range(0, 100), shuffled)
 	})
 
 	t.Run("test chunk", func(t *testing.T) {
@@ -953,3 +1056,106 @@ func ExamplePull() {
 	// output:
 	// 1 true
 }
+
+func TestXIterSeqErr(t *testing.T) {
+	errBad := errors.New("bad")
+	atoi := func(_ int, s string) (int, error) {
+		return strconv.Atoi(s)
+	}
+
+	t.Run("conversion", func(t *testing.T) {
+		seq := xiter.SeqToSeqErr(xiter.FromSlice(_range(0, 10)))
+		values, err := xiter.TryToSlice(seq)
+		assert.Nil(t, err)
+		assert.Equal(t, _range(0, 10), values)
+
+		seq2 := xiter.SeqErrToSeq2(seq)
+		assert.Equal(t, _range(0, 10), xiter.ToSliceSeq2Key(seq2))
+		testLimit2(t, seq2, 1)
+
+		values, err = xiter.TryToSlice(xiter.Seq2ToSeqErr(xiter.Map2(atoi, xiter.FromSliceIdx([]string{"1", "2", "x", "4"}))))
+		assert.Error(t, err)
+		assert.Equal(t, []int{1, 2}, values)
+	})
+
+	t.Run("map err", func(t *testing.T) {
+		seq := xiter.SeqToSeqErr(xiter.FromSlice([]string{"1", "2", "x", "4"}))
+		values, err := xiter.TryToSlice(xiter.MapErr(strconv.Atoi, seq))
+		assert.Error(t, err)
+		assert.Equal(t, []int{1, 2}, values)
+
+		values, err = xiter.TryToSlice(xiter.MapErr(strconv.Atoi, xiter.SeqToSeqErr(xiter.FromSlice([]string{"1", "2"}))))
+		assert.Nil(t, err)
+		assert.Equal(t, []int{1, 2}, values)
+
+		// errors from the source are passed through and stop the iteration
+		src := xiter.Seq2ToSeqErr(xiter.Map2(atoi, xiter.FromSliceIdx([]string{"1", "x", "3"})))
+		calls := 0
+		values, err = xiter.TryToSlice(xiter.MapErr(func(v int) (int, error) {
+			calls++
+			return v * 2, nil
+		}, src))
+		assert.Error(t, err)
+		assert.Equal(t, []int{2}, values)
+		assert.Equal(t, 1, calls)
+
+		_, errs := xiter.CollectErrors(xiter.MapErr(strconv.Atoi, xiter.SeqToSeqErr(xiter.FromSlice([]string{"x", "y"}))))
+		assert.Len(t, errs, 1)
+	})
+
+	t.Run("filter err", func(t *testing.T) {
+		seq := xiter.SeqToSeqErr(xiter.FromSlice(_range(0, 10)))
+		even, err := xiter.TryToSlice(xiter.FilterErr(func(v int) (bool, error) {
+			return v%2 == 0, nil
+		}, seq))
+		assert.Nil(t, err)
+		assert.Equal(t, []int{0, 2, 4, 6, 8}, even)
+
+		values, err := xiter.TryToSlice(xiter.FilterErr(func(v int) (bool, error) {
+			if v == 5 {
+				return false, errBad
+			}
+			return true, nil
+		}, seq))
+		assert.Equal(t, errBad, err)
+		assert.Equal(t, _range(0, 5), values)
+
+		src := xiter.Seq2ToSeqErr(xiter.Map2(atoi, xiter.FromSliceIdx([]string{"1", "x", "3"})))
+		values, err = xiter.TryToSlice(xiter.FilterErr(func(int) (bool, error) { return true, nil }, src))
+		assert.Error(t, err)
+		assert.Equal(t, []int{1}, values)
+	})
+
+	t.Run("try reduce", func(t *testing.T) {
+		add := func(sum int, v int) (int, error) {
+			return sum + v, nil
+		}
+		sum, err := xiter.TryReduce(add, 0, xiter.SeqToSeqErr(xiter.FromSlice(_range(1, 101))))
+		assert.Nil(t, err)
+		assert.Equal(t, 5050, sum)
+
+		sum, err = xiter.TryReduce(func(sum int, v int) (int, error) {
+			if v > 3 {
+				return 0, errBad
+			}
+			return sum + v, nil
+		}, 0, xiter.SeqToSeqErr(xiter.FromSlice(_range(1, 101))))
+		assert.Equal(t, errBad, err)
+		assert.Equal(t, 6, sum)
+
+		sum, err = xiter.TryReduce(add, 0, xiter.Seq2ToSeqErr(xiter.Map2(atoi, xiter.FromSliceIdx([]string{"1", "2", "x", "4"}))))
+		assert.Error(t, err)
+		assert.Equal(t, 3, sum)
+	})
+
+	t.Run("collect errors", func(t *testing.T) {
+		seq := xiter.Seq2ToSeqErr(xiter.Map2(atoi, xiter.FromSliceIdx([]string{"1", "x", "3", "y"})))
+		values, errs := xiter.CollectErrors(seq)
+		assert.Equal(t, []int{1, 3}, values)
+		assert.Len(t, errs, 2)
+
+		values, errs = xiter.CollectErrors(xiter.SeqToSeqErr(xiter.FromSlice(_range(0, 3))))
+		assert.Equal(t, _range(0, 3), values)
+		assert.Len(t, errs, 0)
+	})
+}
@@ -0,0 +1,283 @@
+package xiter
+
+import (
+	"fmt"
+	"time"
+)
+
+// Clock is the source of time used by the time based adapters, such as BatchByTimeOrSize, Throttle,
+// Debounce and SampleEvery. The default one uses the time package, and a fake one can be injected by WithClock
+// to test them without sleeping.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTimer creates a Timer which sends the current time on its channel after at least d.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single event timer created by Clock, like time.Timer.
+type Timer interface {
+	// C returns the channel on which the time is delivered.
+	C() <-chan time.Time
+	// Stop prevents the Timer from firing, it returns false if the timer has already fired or been stopped.
+	Stop() bool
+}
+
+// TimeOption configures the time based adapters.
+type TimeOption func(*timeOptions)
+
+type timeOptions struct {
+	clock Clock
+}
+
+// WithClock makes the time based adapters use c instead of the time package.
+func WithClock(c Clock) TimeOption {
+	return func(o *timeOptions) {
+		o.clock = c
+	}
+}
+
+func newTimeOptions(opts []TimeOption) *timeOptions {
+	o := &timeOptions{clock: realClock{}}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTimer(d time.Duration) Timer { return realTimer{t: time.NewTimer(d)} }
+
+type realTimer struct {
+	t *time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time { return t.t.C }
+
+func (t realTimer) Stop() bool { return t.t.Stop() }
+
+// clockTimer is a Timer which may be inactive, the channel of an inactive one is nil and blocks forever in select.
+type clockTimer struct {
+	clock Clock
+	t     Timer
+}
+
+func (c *clockTimer) start(d time.Duration) {
+	c.stop()
+	c.t = c.clock.NewTimer(d)
+}
+
+func (c *clockTimer) stop() {
+	if c.t != nil {
+		c.t.Stop()
+		c.t = nil
+	}
+}
+
+// fired marks the timer inactive after its channel is received.
+func (c *clockTimer) fired() {
+	c.t = nil
+}
+
+func (c *clockTimer) active() bool {
+	return c.t != nil
+}
+
+func (c *clockTimer) C() <-chan time.Time {
+	if c.t == nil {
+		return nil
+	}
+	return c.t.C()
+}
+
+// BatchByTimeOrSize returns a Seq over batches of the elements of seq, a batch is yielded when it has maxN elements,
+// or maxWait has passed since its first element was received, whichever comes first.
+// The last batch may be smaller, and an empty batch is never yielded.
+//
+// seq runs on its own goroutine, which is useful for sources producing elements over time like FromChan.
+// When the consumer stops iterating, the goroutine is told to stop but not waited for, so a seq blocked on a quiet source
+// does not block the consumer, and the goroutine exits when seq yields again or returns.
+// If seq panics, the panic is propagated to the consumer. It panics if maxN or maxWait is not positive.
+//
+// EXAMPLE:
+//
+//	events := make(chan Event)
+//	for batch := range xiter.BatchByTimeOrSize(xiter.FromChan(events), 100, time.Second) {
+//		sink.Write(batch) // at most 100 events, and an event waits for at most 1 second
+//	}
+func BatchByTimeOrSize[T any](seq Seq[T], maxN int, maxWait time.Duration, opts ...TimeOption) Seq[[]T] {
+	mustBePositive("maxN", maxN)
+	mustBePositiveDuration("maxWait", maxWait)
+	o := newTimeOptions(opts)
+	return func(yield func([]T) bool) {
+		ch, stop := ToChanOpts(seq)
+		defer stop()
+		timer := &clockTimer{clock: o.clock}
+		defer timer.stop()
+		var batch []T
+		flush := func() bool {
+			timer.stop()
+			out := batch
+			batch = nil
+			return yield(out)
+		}
+		for {
+			select {
+			case v, ok := <-ch:
+				if !ok {
+					if len(batch) > 0 {
+						flush()
+					}
+					return
+				}
+				if batch == nil {
+					batch = make([]T, 0, maxN)
+					timer.start(maxWait)
+				}
+				batch = append(batch, v)
+				if len(batch) >= maxN && !flush() {
+					return
+				}
+			case <-timer.C():
+				timer.fired()
+				if !flush() {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Throttle returns a Seq which yields the elements of seq with at least interval between two of them,
+// it waits before yielding an element if the previous one was yielded less than interval ago.
+// No element is dropped, so seq is pulled at the rate of the consumer. It panics if interval is negative.
+//
+// EXAMPLE:
+//
+//	for req := range xiter.Throttle(requests, 100*time.Millisecond) {
+//		send(req) // at most 10 requests per second
+//	}
+func Throttle[T any](seq Seq[T], interval time.Duration, opts ...TimeOption) Seq[T] {
+	if interval < 0 {
+		panic(fmt.Sprintf("interval %v must not be negative", interval))
+	}
+	o := newTimeOptions(opts)
+	return func(yield func(T) bool) {
+		var last time.Time
+		first := true
+		seq(func(v T) bool {
+			if !first {
+				if d := interval - o.clock.Now().Sub(last); d > 0 {
+					<-o.clock.NewTimer(d).C()
+				}
+			}
+			first = false
+			last = o.clock.Now()
+			return yield(v)
+		})
+	}
+}
+
+// Debounce returns a Seq which yields an element of seq only after no newer element is received for wait,
+// so a burst of elements results in its last element. The pending element is yielded when seq is exhausted.
+//
+// Like BatchByTimeOrSize, seq runs on its own goroutine,
+// which is told to stop but not waited for when the consumer stops iterating.
+// It panics if wait is not positive.
+//
+// EXAMPLE:
+//
+//	for query := range xiter.Debounce(xiter.FromChan(keystrokes), 300*time.Millisecond) {
+//		search(query) // only when the user stops typing for 300ms
+//	}
+func Debounce[T any](seq Seq[T], wait time.Duration, opts ...TimeOption) Seq[T] {
+	mustBePositiveDuration("wait", wait)
+	o := newTimeOptions(opts)
+	return func(yield func(T) bool) {
+		ch, stop := ToChanOpts(seq)
+		defer stop()
+		timer := &clockTimer{clock: o.clock}
+		defer timer.stop()
+		var pending T
+		for {
+			select {
+			case v, ok := <-ch:
+				if !ok {
+					if timer.active() {
+						yield(pending)
+					}
+					return
+				}
+				pending = v
+				timer.start(wait)
+			case <-timer.C():
+				timer.fired()
+				if !yield(pending) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// SampleEvery returns a Seq which yields the latest element of seq received in every interval,
+// nothing is yielded for an interval without new elements. The latest element received after the last interval
+// is yielded when seq is exhausted.
+//
+// Like BatchByTimeOrSize, seq runs on its own goroutine,
+// which is told to stop but not waited for when the consumer stops iterating.
+// It panics if interval is not positive.
+//
+// EXAMPLE:
+//
+//	for temperature := range xiter.SampleEvery(xiter.FromChan(readings), time.Minute) {
+//		record(temperature) // at most one reading per minute
+//	}
+func SampleEvery[T any](seq Seq[T], interval time.Duration, opts ...TimeOption) Seq[T] {
+	mustBePositiveDuration("interval", interval)
+	o := newTimeOptions(opts)
+	return func(yield func(T) bool) {
+		ch, stop := ToChanOpts(seq)
+		defer stop()
+		timer := &clockTimer{clock: o.clock}
+		defer timer.stop()
+		timer.start(interval)
+		var latest T
+		fresh := false
+		for {
+			select {
+			case v, ok := <-ch:
+				if !ok {
+					if fresh {
+						yield(latest)
+					}
+					return
+				}
+				latest, fresh = v, true
+			case <-timer.C():
+				timer.start(interval)
+				if fresh {
+					fresh = false
+					if !yield(latest) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+func mustBePositive(name string, v int) {
+	if v <= 0 {
+		panic(fmt.Sprintf("%s %d must be positive", name, v))
+	}
+}
+
+func mustBePositiveDuration(name string, d time.Duration) {
+	if d <= 0 {
+		panic(fmt.Sprintf("%s %v must be positive", name, d))
+	}
+}
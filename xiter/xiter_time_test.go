@@ -0,0 +1,250 @@
+package xiter_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dashjay/xiter/xiter"
+)
+
+// fakeClock is a xiter.Clock whose time only moves by advance.
+// Its timers deliver on unbuffered channels, so advance returns only after every fired timer is received or stopped,
+// which makes the adapters under test deterministic.
+type fakeClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	timers  []*fakeTimer
+	created int
+}
+
+type fakeTimer struct {
+	clock   *fakeClock
+	at      time.Time
+	c       chan time.Time
+	stopped chan struct{}
+	closed  bool
+}
+
+func newFakeClock() *fakeClock {
+	c := &fakeClock{now: time.Unix(0, 0)}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) xiter.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{clock: c, at: c.now.Add(d), c: make(chan time.Time), stopped: make(chan struct{})}
+	c.timers = append(c.timers, t)
+	c.created++
+	c.cond.Broadcast()
+	return t
+}
+
+// waitCreated blocks until n timers have been created.
+func (c *fakeClock) waitCreated(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.created < n {
+		c.cond.Wait()
+	}
+}
+
+// advance moves the time forward by d and fires the due timers.
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	now := c.now
+	var due []*fakeTimer
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if !t.at.After(now) {
+			due = append(due, t)
+		} else {
+			pending = append(pending, t)
+		}
+	}
+	c.timers = pending
+	c.mu.Unlock()
+	for _, t := range due {
+		select {
+		case t.c <- now:
+		case <-t.stopped:
+		}
+	}
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+func (t *fakeTimer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t.closed {
+		return false
+	}
+	t.closed = true
+	close(t.stopped)
+	for i, pending := range c.timers {
+		if pending == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func TestXIterTime(t *testing.T) {
+	boom := xiter.Seq[int](func(yield func(int) bool) {
+		yield(1)
+		panic("boom")
+	})
+
+	t.Run("batch by time or size", func(t *testing.T) {
+		seq := xiter.BatchByTimeOrSize(xiter.FromSlice(_range(0, 10)), 3, time.Hour)
+		assert.Equal(t, [][]int{{0, 1, 2}, {3, 4, 5}, {6, 7, 8}, {9}}, xiter.ToSlice(seq))
+		testLimit(t, seq, 2)
+		assert.Len(t, xiter.ToSlice(xiter.BatchByTimeOrSize(xiter.FromSlice([]int{}), 3, time.Hour)), 0)
+
+		clock := newFakeClock()
+		src := xiter.Seq[int](func(yield func(int) bool) {
+			_ = yield(1) && yield(2) && yield(3)
+			yield(4)
+			clock.waitCreated(2)
+			clock.advance(time.Second)
+			_ = yield(5) && yield(6)
+		})
+		seq = xiter.BatchByTimeOrSize(src, 3, time.Second, xiter.WithClock(clock))
+		assert.Equal(t, [][]int{{1, 2, 3}, {4}, {5, 6}}, xiter.ToSlice(seq))
+
+		ng := stableNumGoroutine()
+		seq = xiter.BatchByTimeOrSize(xiter.Range(0, 1<<62, 1), 2, time.Hour)
+		assert.Equal(t, [][]int{{0, 1}, {2, 3}}, xiter.ToSlice(xiter.Limit(seq, 2)))
+		assert.Equal(t, ng, stableNumGoroutine())
+
+		assert.PanicsWithValue(t, "boom", func() { xiter.ToSlice(xiter.BatchByTimeOrSize(boom, 2, time.Hour)) })
+		assert.Panics(t, func() { xiter.BatchByTimeOrSize(boom, 0, time.Hour) })
+		assert.Panics(t, func() { xiter.BatchByTimeOrSize(boom, 1, 0) })
+	})
+
+	t.Run("throttle", func(t *testing.T) {
+		assert.Equal(t, _range(0, 10), xiter.ToSlice(xiter.Throttle(xiter.FromSlice(_range(0, 10)), 0)))
+		testLimit(t, xiter.Throttle(xiter.FromSlice(_range(0, 10)), 0), 3)
+
+		clock := newFakeClock()
+		var times []time.Duration
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			xiter.Throttle(xiter.FromSlice(_range(0, 4)), time.Second, xiter.WithClock(clock))(func(v int) bool {
+				times = append(times, clock.Now().Sub(time.Unix(0, 0)))
+				if v == 1 {
+					// the next element is already due
+					clock.advance(2 * time.Second)
+				}
+				return true
+			})
+		}()
+		clock.waitCreated(1)
+		clock.advance(time.Second)
+		clock.waitCreated(2)
+		clock.advance(time.Second / 2)
+		clock.advance(time.Second / 2)
+		<-done
+		assert.Equal(t, []time.Duration{0, time.Second, 3 * time.Second, 4 * time.Second}, times)
+
+		assert.Panics(t, func() { xiter.Throttle(boom, -1) })
+	})
+
+	t.Run("debounce", func(t *testing.T) {
+		assert.Equal(t, []int{9}, xiter.ToSlice(xiter.Debounce(xiter.FromSlice(_range(0, 10)), time.Hour)))
+		assert.Len(t, xiter.ToSlice(xiter.Debounce(xiter.FromSlice([]int{}), time.Hour)), 0)
+
+		clock := newFakeClock()
+		src := xiter.Seq[int](func(yield func(int) bool) {
+			_ = yield(1) && yield(2) && yield(3)
+			clock.waitCreated(3)
+			clock.advance(time.Second)
+			yield(4)
+			clock.waitCreated(4)
+			clock.advance(time.Second / 2)
+			yield(5)
+		})
+		seq := xiter.Debounce(src, time.Second, xiter.WithClock(clock))
+		assert.Equal(t, []int{3, 5}, xiter.ToSlice(seq))
+
+		ng := stableNumGoroutine()
+		clock = newFakeClock()
+		src = func(yield func(int) bool) {
+			for i := 0; yield(i); i++ {
+				clock.waitCreated(i + 1)
+				clock.advance(time.Second)
+			}
+		}
+		assert.Equal(t, _range(0, 5), xiter.ToSlice(xiter.Limit(xiter.Debounce(src, time.Second, xiter.WithClock(clock)), 5)))
+		assert.Equal(t, ng, stableNumGoroutine())
+
+		assert.PanicsWithValue(t, "boom", func() { xiter.ToSlice(xiter.Debounce(boom, time.Hour)) })
+		assert.Panics(t, func() { xiter.Debounce(boom, 0) })
+	})
+
+	t.Run("sample every", func(t *testing.T) {
+		assert.Equal(t, []int{9}, xiter.ToSlice(xiter.SampleEvery(xiter.FromSlice(_range(0, 10)), time.Hour)))
+
+		clock := newFakeClock()
+		src := xiter.Seq[int](func(yield func(int) bool) {
+			_ = yield(1) && yield(2)
+			clock.waitCreated(1)
+			clock.advance(time.Second)
+			// an interval without new elements
+			clock.waitCreated(2)
+			clock.advance(time.Second)
+			clock.waitCreated(3)
+			yield(3)
+		})
+		seq := xiter.SampleEvery(src, time.Second, xiter.WithClock(clock))
+		assert.Equal(t, []int{2, 3}, xiter.ToSlice(seq))
+
+		assert.PanicsWithValue(t, "boom", func() { xiter.ToSlice(xiter.SampleEvery(boom, time.Hour)) })
+		assert.Panics(t, func() { xiter.SampleEvery(boom, 0) })
+	})
+	t.Run("early stop over a blocking source", func(t *testing.T) {
+		// each adapter yields once and is stopped, while its source is blocked on a quiet channel
+		adapters := []func(xiter.Seq[int]) int{
+			func(src xiter.Seq[int]) int {
+				return len(xiter.ToSlice(xiter.Limit(xiter.BatchByTimeOrSize(src, 1, time.Hour), 1)))
+			},
+			func(src xiter.Seq[int]) int {
+				return len(xiter.ToSlice(xiter.Limit(xiter.Debounce(src, time.Millisecond), 1)))
+			},
+			func(src xiter.Seq[int]) int {
+				return len(xiter.ToSlice(xiter.Limit(xiter.SampleEvery(src, time.Millisecond), 1)))
+			},
+		}
+		for _, adapter := range adapters {
+			in := make(chan int, 1)
+			in <- 1
+			done := make(chan int)
+			go func(adapter func(xiter.Seq[int]) int) {
+				done <- adapter(xiter.FromChan(in))
+			}(adapter)
+			select {
+			case n := <-done:
+				assert.Equal(t, 1, n)
+			case <-time.After(time.Second):
+				t.Fatal("the consumer blocks on the source after it stops iterating")
+			}
+			close(in)
+		}
+	})
+}
@@ -0,0 +1,167 @@
+package xiter
+
+import (
+	"container/list"
+	"fmt"
+	"hash/maphash"
+	"math"
+)
+
+// UniqBy returns a seq that removes the elements whose keys extracted by f have been seen,
+// only the first element of every key is yielded. Like Uniq, it keeps all seen keys in memory.
+//
+// EXAMPLE:
+//
+//	seq := xiter.FromSlice([]string{"apple", "avocado", "banana", "blueberry", "cherry"})
+//	xiter.UniqBy(seq, func(s string) byte { return s[0] }) 👉 [apple banana cherry]
+func UniqBy[T any, K comparable](seq Seq[T], f func(T) K) Seq[T] {
+	return func(yield func(T) bool) {
+		seen := make(map[K]struct{})
+		seq(func(v T) bool {
+			k := f(v)
+			if _, ok := seen[k]; ok {
+				return true
+			}
+			seen[k] = struct{}{}
+			return yield(v)
+		})
+	}
+}
+
+// UniqConsecutive returns a seq that removes the elements equal to their previous ones,
+// so only the first element of every run of equal elements is yielded. It uses O(1) memory,
+// and removes all duplicates if seq is sorted.
+//
+// EXAMPLE:
+//
+//	xiter.UniqConsecutive(xiter.FromSlice([]int{1, 1, 2, 2, 2, 1, 3})) 👉 [1 2 1 3]
+func UniqConsecutive[T comparable](seq Seq[T]) Seq[T] {
+	return UniqConsecutiveBy(seq, func(v T) T { return v })
+}
+
+// UniqConsecutiveBy is like UniqConsecutive, but the elements are compared by their keys extracted by f.
+//
+// EXAMPLE:
+//
+//	seq := xiter.FromSlice([]string{"apple", "avocado", "banana", "apricot"})
+//	xiter.UniqConsecutiveBy(seq, func(s string) byte { return s[0] }) 👉 [apple banana apricot]
+func UniqConsecutiveBy[T any, K comparable](seq Seq[T], f func(T) K) Seq[T] {
+	return func(yield func(T) bool) {
+		var last K
+		first := true
+		seq(func(v T) bool {
+			k := f(v)
+			if !first && k == last {
+				return true
+			}
+			first = false
+			last = k
+			return yield(v)
+		})
+	}
+}
+
+// UniqWindow returns a seq that removes the elements seen among the last size distinct elements,
+// it keeps at most size elements in memory, so it works with infinite seqs.
+// The seen elements are evicted in least recently used order, and seeing a duplicate refreshes it,
+// so an element repeated often enough is never yielded twice. It panics if size is not positive.
+//
+// EXAMPLE:
+//
+//	xiter.UniqWindow(xiter.FromSlice([]int{1, 2, 1, 3, 4, 1, 2}), 2) 👉 [1 2 3 4 1 2]
+func UniqWindow[T comparable](seq Seq[T], size int) Seq[T] {
+	return UniqWindowBy(seq, size, func(v T) T { return v })
+}
+
+// UniqWindowBy is like UniqWindow, but the elements are compared by their keys extracted by f.
+func UniqWindowBy[T any, K comparable](seq Seq[T], size int, f func(T) K) Seq[T] {
+	mustBePositive("size", size)
+	return func(yield func(T) bool) {
+		// recent holds the seen keys, the most recently used one in the front.
+		recent := list.New()
+		seen := make(map[K]*list.Element, size)
+		seq(func(v T) bool {
+			k := f(v)
+			if e, ok := seen[k]; ok {
+				recent.MoveToFront(e)
+				return true
+			}
+			seen[k] = recent.PushFront(k)
+			if recent.Len() > size {
+				delete(seen, recent.Remove(recent.Back()).(K))
+			}
+			return yield(v)
+		})
+	}
+}
+
+// UniqBloom returns a seq that removes the elements whose keys extracted by f have been seen,
+// by a Bloom filter sized for expected keys with the false positive rate fpRate, instead of a map.
+// The memory is fixed, about -expected*ln(fpRate)/ln(2)^2 bits, but an element may be dropped as a duplicate
+// by mistake, at the rate of fpRate if there are no more than expected keys, and more often if there are more.
+// A duplicate is never yielded. It panics if expected is not positive or fpRate is not in (0, 1).
+//
+// EXAMPLE:
+//
+//	seq := xiter.UniqBloom(events, 1_000_000, 0.001, func(e Event) string { return e.ID })
+//	// about 1.8MB of memory for 1 million events, and about 1000 unique events are dropped
+func UniqBloom[T any](seq Seq[T], expected int, fpRate float64, f func(T) string) Seq[T] {
+	mustBePositive("expected", expected)
+	if !(fpRate > 0 && fpRate < 1) {
+		panic(fmt.Sprintf("fpRate %v must be in (0, 1)", fpRate))
+	}
+	return func(yield func(T) bool) {
+		filter := newBloomFilter(expected, fpRate)
+		seq(func(v T) bool {
+			if !filter.add(f(v)) {
+				return true
+			}
+			return yield(v)
+		})
+	}
+}
+
+// bloomFilter is a Bloom filter over strings with k bit positions for every key by double hashing.
+type bloomFilter struct {
+	bits   []uint64
+	m      uint64
+	k      int
+	seeds  [2]maphash.Seed
+	hasher maphash.Hash
+}
+
+func newBloomFilter(n int, p float64) *bloomFilter {
+	m := math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2))
+	k := int(math.Round(m / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	words := (uint64(m) + 63) / 64
+	return &bloomFilter{
+		bits:  make([]uint64, words),
+		m:     words * 64,
+		k:     k,
+		seeds: [2]maphash.Seed{maphash.MakeSeed(), maphash.MakeSeed()},
+	}
+}
+
+func (b *bloomFilter) hash(seed maphash.Seed, key string) uint64 {
+	b.hasher.SetSeed(seed)
+	_, _ = b.hasher.WriteString(key)
+	return b.hasher.Sum64()
+}
+
+// add adds key to the filter, it returns false if key may have been added before.
+func (b *bloomFilter) add(key string) bool {
+	h1, h2 := b.hash(b.seeds[0], key), b.hash(b.seeds[1], key)|1
+	added := false
+	for i := 0; i < b.k; i++ {
+		pos := (h1 + uint64(i)*h2) % b.m
+		word, mask := pos/64, uint64(1)<<(pos%64)
+		if b.bits[word]&mask == 0 {
+			b.bits[word] |= mask
+			added = true
+		}
+	}
+	return added
+}
@@ -0,0 +1,83 @@
+package xiter_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dashjay/xiter/xiter"
+)
+
+func TestXIterUniq(t *testing.T) {
+	firstByte := func(s string) byte { return s[0] }
+
+	t.Run("uniq by", func(t *testing.T) {
+		seq := xiter.FromSlice([]string{"apple", "avocado", "banana", "blueberry", "cherry"})
+		assert.Equal(t, []string{"apple", "banana", "cherry"}, xiter.ToSlice(xiter.UniqBy(seq, firstByte)))
+		// the seen keys are not shared between iterations
+		assert.Equal(t, []string{"apple", "banana", "cherry"}, xiter.ToSlice(xiter.UniqBy(seq, firstByte)))
+		testLimit(t, xiter.UniqBy(seq, firstByte), 2)
+		assert.Len(t, xiter.ToSlice(xiter.UniqBy(xiter.FromSlice([]string{}), firstByte)), 0)
+	})
+
+	t.Run("uniq consecutive", func(t *testing.T) {
+		seq := xiter.FromSlice([]int{1, 1, 2, 2, 2, 1, 3, 3})
+		assert.Equal(t, []int{1, 2, 1, 3}, xiter.ToSlice(xiter.UniqConsecutive(seq)))
+		testLimit(t, xiter.UniqConsecutive(seq), 3)
+		assert.Len(t, xiter.ToSlice(xiter.UniqConsecutive(xiter.FromSlice([]int{}))), 0)
+
+		words := xiter.FromSlice([]string{"apple", "avocado", "banana", "apricot"})
+		assert.Equal(t, []string{"apple", "banana", "apricot"}, xiter.ToSlice(xiter.UniqConsecutiveBy(words, firstByte)))
+
+		// the zero value is not treated as the previous element of the first one
+		assert.Equal(t, []int{0, 1, 0}, xiter.ToSlice(xiter.UniqConsecutive(xiter.FromSlice([]int{0, 0, 1, 0}))))
+	})
+
+	t.Run("uniq window", func(t *testing.T) {
+		seq := xiter.FromSlice([]int{1, 2, 1, 3, 4, 1, 2})
+		assert.Equal(t, []int{1, 2, 3, 4, 1, 2}, xiter.ToSlice(xiter.UniqWindow(seq, 2)))
+		assert.Equal(t, []int{1, 2, 3, 4}, xiter.ToSlice(xiter.UniqWindow(seq, 4)))
+		assert.Equal(t, []int{1, 2, 1, 3, 4, 1, 2}, xiter.ToSlice(xiter.UniqWindow(seq, 1)))
+		testLimit(t, xiter.UniqWindow(seq, 2), 3)
+
+		// a repeated element is refreshed and never evicted
+		hot := xiter.FromSlice([]int{0, 1, 0, 2, 0, 3, 0, 4})
+		assert.Equal(t, []int{0, 1, 2, 3, 4}, xiter.ToSlice(xiter.UniqWindow(hot, 2)))
+
+		// infinite seq with bounded memory
+		cycled := xiter.Map(func(v int) int { return v % 10 }, xiter.Range(0, 1<<62, 1))
+		assert.Equal(t, _range(0, 10), xiter.ToSlice(xiter.Limit(xiter.UniqWindow(cycled, 10), 10)))
+		assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 0, 1}, xiter.ToSlice(xiter.Limit(xiter.UniqWindow(cycled, 9), 12)))
+
+		words := xiter.FromSlice([]string{"apple", "banana", "avocado", "cherry", "apricot"})
+		assert.Equal(t, []string{"apple", "banana", "avocado", "cherry", "apricot"}, xiter.ToSlice(xiter.UniqWindowBy(words, 1, firstByte)))
+		assert.Equal(t, []string{"apple", "banana", "cherry"}, xiter.ToSlice(xiter.UniqWindowBy(words, 3, firstByte)))
+
+		assert.Panics(t, func() { xiter.UniqWindow(seq, 0) })
+	})
+
+	t.Run("uniq bloom", func(t *testing.T) {
+		seq := xiter.FromSlice([]string{"a", "b", "a", "c", "b", "d"})
+		assert.Equal(t, []string{"a", "b", "c", "d"}, xiter.ToSlice(xiter.UniqBloom(seq, 100, 0.01, func(s string) string { return s })))
+		testLimit(t, xiter.UniqBloom(seq, 100, 0.01, func(s string) string { return s }), 2)
+
+		// duplicates are never yielded, and the false positive rate is close to the configured one
+		const n = 20000
+		keys := xiter.Map(func(v int) string { return strconv.Itoa(v % n) }, xiter.Range(0, 2*n, 1))
+		uniq := xiter.ToSlice(xiter.UniqBloom(keys, n, 0.01, func(s string) string { return s }))
+		seen := make(map[string]struct{})
+		for _, k := range uniq {
+			_, ok := seen[k]
+			assert.False(t, ok)
+			seen[k] = struct{}{}
+		}
+		dropped := n - len(uniq)
+		assert.Greater(t, dropped, 0)
+		assert.Less(t, dropped, n*3/100)
+
+		assert.Panics(t, func() { xiter.UniqBloom(seq, 0, 0.01, func(s string) string { return s }) })
+		assert.Panics(t, func() { xiter.UniqBloom(seq, 10, 0, func(s string) string { return s }) })
+		assert.Panics(t, func() { xiter.UniqBloom(seq, 10, 1, func(s string) string { return s }) })
+	})
+}
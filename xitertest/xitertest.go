@@ -0,0 +1,314 @@
+// Package xitertest checks that xiter.Seq and xiter.Seq2 implementations follow the iterator protocol,
+// like testing/iotest does for io.Reader.
+//
+// A seq must not call yield again after yield returns false, a panic of yield must reach the caller of the seq,
+// and no goroutine started by the seq may be left behind when it returns. A seq over a slice, or built from
+// other re-iterable seqs, should also yield the same elements every time it is iterated, even after a partial
+// iteration, which catches the state captured outside the returned closure by mistake.
+//
+// TestSeq and TestSeq2 run all the checks and report the violations to a testing.TB,
+// and the Check* functions run one check and return the violation as an error.
+package xitertest
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/dashjay/xiter/union"
+	"github.com/dashjay/xiter/xiter"
+)
+
+const (
+	defaultMaxElements = 1000
+	// edgeElements is the number of elements at both ends of a seq, where an iteration is stopped at every element.
+	edgeElements = 16
+	leakTimeout  = time.Second
+)
+
+// Option configures the checks.
+type Option func(*options)
+
+type options struct {
+	maxElements      int
+	nondeterministic bool
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{maxElements: defaultMaxElements}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithMaxElements sets the max number of elements consumed by every iteration, the default is 1000.
+// A longer seq, including an infinite one, is checked on its first n elements. It panics if n is not positive.
+func WithMaxElements(n int) Option {
+	if n <= 0 {
+		panic(fmt.Sprintf("max elements %d must be positive", n))
+	}
+	return func(o *options) {
+		o.maxElements = n
+	}
+}
+
+// WithNondeterministic tells the checks that the seq may yield different elements every time,
+// such as a random sample or a merge of goroutines, so the outputs of different iterations are not compared.
+func WithNondeterministic() Option {
+	return func(o *options) {
+		o.nondeterministic = true
+	}
+}
+
+// TestSeq runs all the checks on seq, and reports the violations by t.Error.
+// seq is iterated many times, use TestSeqFunc if it can be iterated only once.
+//
+// EXAMPLE:
+//
+//	func TestLimit(t *testing.T) {
+//		xitertest.TestSeq(t, xiter.Limit(xiter.FromSlice([]int{1, 2, 3}), 2))
+//	}
+func TestSeq[T any](t testing.TB, seq xiter.Seq[T], opts ...Option) {
+	t.Helper()
+	o := newOptions(opts)
+	newSeq := func() xiter.Seq[T] { return seq }
+	// the outputs are compared first, before the other checks change the state captured by mistake.
+	if !o.nondeterministic {
+		report(t, checkDeterministic(newSeq, o))
+		report(t, checkReiterable(seq, o))
+	}
+	report(t, checkNoYieldAfterFalse(newSeq, o))
+	report(t, checkPanicPropagation(newSeq, o))
+	report(t, checkNoGoroutineLeak(newSeq, o))
+}
+
+// TestSeqFunc is like TestSeq, but every iteration is made on a new seq returned by newSeq,
+// so it works with the seqs which can be iterated only once, such as xiter.FromChan.
+// The seqs returned by newSeq are expected to yield the same elements, unless WithNondeterministic is given.
+//
+// EXAMPLE:
+//
+//	xitertest.TestSeqFunc(t, func() xiter.Seq[int] {
+//		ch := make(chan int, 3)
+//		ch <- 1; ch <- 2; ch <- 3
+//		close(ch)
+//		return xiter.FromChan(ch)
+//	})
+func TestSeqFunc[T any](t testing.TB, newSeq func() xiter.Seq[T], opts ...Option) {
+	t.Helper()
+	o := newOptions(opts)
+	if !o.nondeterministic {
+		report(t, checkDeterministic(newSeq, o))
+	}
+	report(t, checkNoYieldAfterFalse(newSeq, o))
+	report(t, checkPanicPropagation(newSeq, o))
+	report(t, checkNoGoroutineLeak(newSeq, o))
+}
+
+// TestSeq2 is like TestSeq but checks a Seq2.
+func TestSeq2[K, V any](t testing.TB, seq xiter.Seq2[K, V], opts ...Option) {
+	t.Helper()
+	TestSeq(t, xiter.Seq2ToSeqUnion(seq), opts...)
+}
+
+// TestSeq2Func is like TestSeqFunc but checks the Seq2 returned by newSeq.
+func TestSeq2Func[K, V any](t testing.TB, newSeq func() xiter.Seq2[K, V], opts ...Option) {
+	t.Helper()
+	TestSeqFunc(t, func() xiter.Seq[union.U2[K, V]] { return xiter.Seq2ToSeqUnion(newSeq()) }, opts...)
+}
+
+// CheckNoYieldAfterFalse stops iterating seq at every element near both ends and in the middle,
+// and returns an error if yield is called again after it returns false.
+func CheckNoYieldAfterFalse[T any](seq xiter.Seq[T], opts ...Option) error {
+	return checkNoYieldAfterFalse(func() xiter.Seq[T] { return seq }, newOptions(opts))
+}
+
+// CheckDeterministic iterates seq several times, and returns an error if the elements are not the same every time.
+func CheckDeterministic[T any](seq xiter.Seq[T], opts ...Option) error {
+	return checkDeterministic(func() xiter.Seq[T] { return seq }, newOptions(opts))
+}
+
+// CheckReiterable stops iterating seq at some elements, and returns an error if the next iteration
+// does not yield the same elements as the first one.
+func CheckReiterable[T any](seq xiter.Seq[T], opts ...Option) error {
+	return checkReiterable(seq, newOptions(opts))
+}
+
+// CheckPanicPropagation panics in yield at the first and the last element of seq,
+// and returns an error if seq does not panic with the same value.
+func CheckPanicPropagation[T any](seq xiter.Seq[T], opts ...Option) error {
+	return checkPanicPropagation(func() xiter.Seq[T] { return seq }, newOptions(opts))
+}
+
+// CheckNoGoroutineLeak iterates seq to the end, stops it at the first element, and panics in yield at the first element,
+// and returns an error if there are more goroutines after any of them than before.
+// The goroutines exiting in the background are waited for at most one second.
+func CheckNoGoroutineLeak[T any](seq xiter.Seq[T], opts ...Option) error {
+	return checkNoGoroutineLeak(func() xiter.Seq[T] { return seq }, newOptions(opts))
+}
+
+func report(t testing.TB, err error) {
+	t.Helper()
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func checkNoYieldAfterFalse[T any](newSeq func() xiter.Seq[T], o *options) error {
+	all, err := collect(newSeq(), o.maxElements)
+	if err != nil {
+		return err
+	}
+	for _, k := range stopPoints(len(all)) {
+		if _, err = collect(newSeq(), k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkDeterministic[T any](newSeq func() xiter.Seq[T], o *options) error {
+	// the errors of collect are reported by checkNoYieldAfterFalse.
+	first, _ := collect(newSeq(), o.maxElements)
+	for i := 2; i <= 3; i++ {
+		if out, _ := collect(newSeq(), o.maxElements); !reflect.DeepEqual(first, out) {
+			return fmt.Errorf("xitertest: iteration %d yields %v, but the first one yields %v", i, out, first)
+		}
+	}
+	return nil
+}
+
+func checkReiterable[T any](seq xiter.Seq[T], o *options) error {
+	first, _ := collect(seq, o.maxElements)
+	if len(first) == 0 {
+		return nil
+	}
+	for _, k := range []int{1, len(first) / 2, len(first)} {
+		if k == 0 {
+			continue
+		}
+		_, _ = collect(seq, k)
+		if out, _ := collect(seq, o.maxElements); !reflect.DeepEqual(first, out) {
+			return fmt.Errorf("xitertest: after an iteration stopped at element %d, seq yields %v, but the first iteration yields %v",
+				k, out, first)
+		}
+	}
+	return nil
+}
+
+func checkPanicPropagation[T any](newSeq func() xiter.Seq[T], o *options) error {
+	all, _ := collect(newSeq(), o.maxElements)
+	if len(all) == 0 {
+		return nil
+	}
+	for _, k := range []int{1, len(all)} {
+		want := &yieldPanic{element: k}
+		got, reached := panicAt(newSeq(), k, want)
+		if !reached {
+			// a nondeterministic seq may yield less elements this time.
+			continue
+		}
+		if got != want {
+			if got == nil {
+				return fmt.Errorf("xitertest: yield panics at element %d, but the panic does not reach the caller", k)
+			}
+			return fmt.Errorf("xitertest: yield panics at element %d, but seq panics with %v", k, got)
+		}
+	}
+	return nil
+}
+
+func checkNoGoroutineLeak[T any](newSeq func() xiter.Seq[T], o *options) error {
+	runs := []struct {
+		name string
+		run  func()
+	}{
+		{"a full iteration", func() { _, _ = collect(newSeq(), o.maxElements) }},
+		{"an iteration stopped at element 1", func() { _, _ = collect(newSeq(), 1) }},
+		{"a panic of yield at element 1", func() { _, _ = panicAt(newSeq(), 1, &yieldPanic{element: 1}) }},
+	}
+	for _, r := range runs {
+		before := runtime.NumGoroutine()
+		r.run()
+		if after := waitGoroutines(before); after > before {
+			return fmt.Errorf("xitertest: %d goroutines are left after %s", after-before, r.name)
+		}
+	}
+	return nil
+}
+
+// yieldPanic is the value yield panics with, a pointer to it is only equal to itself.
+type yieldPanic struct {
+	element int
+}
+
+func (p *yieldPanic) String() string {
+	return fmt.Sprintf("xitertest: panic at element %d", p.element)
+}
+
+// collect returns at most n elements of seq, yield returns false at the nth element.
+// It returns an error if yield is called after it returns false, or seq panics.
+func collect[T any](seq xiter.Seq[T], n int) (out []T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("xitertest: seq panics: %v", r)
+		}
+	}()
+	stopped := false
+	seq(func(v T) bool {
+		if stopped {
+			if err == nil {
+				err = fmt.Errorf("xitertest: yield is called again after it returns false at element %d", n)
+			}
+			return false
+		}
+		out = append(out, v)
+		stopped = len(out) >= n
+		return !stopped
+	})
+	return out, err
+}
+
+// panicAt iterates seq with a yield which panics with p at the kth element, and returns the value seq panics with,
+// reached is false if seq has less than k elements.
+func panicAt[T any](seq xiter.Seq[T], k int, p *yieldPanic) (r any, reached bool) {
+	defer func() {
+		r = recover()
+	}()
+	i := 0
+	seq(func(T) bool {
+		i++
+		if i == k {
+			reached = true
+			panic(p)
+		}
+		return true
+	})
+	return nil, reached
+}
+
+// stopPoints returns the elements to stop a seq of n elements at.
+func stopPoints(n int) []int {
+	var points []int
+	for k := 1; k <= n; k++ {
+		if k <= edgeElements || k > n-edgeElements || k == n/2 {
+			points = append(points, k)
+		}
+	}
+	return points
+}
+
+// waitGoroutines waits for the number of goroutines to go down to want, and returns the last number.
+func waitGoroutines(want int) int {
+	deadline := time.Now().Add(leakTimeout)
+	for {
+		n := runtime.NumGoroutine()
+		if n <= want || time.Now().After(deadline) {
+			return n
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,193 @@
+package xitertest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dashjay/xiter/xiter"
+	"github.com/dashjay/xiter/xitertest"
+)
+
+// recorder records the errors instead of failing the test.
+type recorder struct {
+	testing.TB
+	errs []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Error(args ...any) {
+	r.errs = append(r.errs, fmt.Sprint(args...))
+}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+func ints(n int) xiter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := 0; i < n; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+func TestXIterTest(t *testing.T) {
+	t.Run("conforming", func(t *testing.T) {
+		xitertest.TestSeq(t, ints(0))
+		xitertest.TestSeq(t, ints(100))
+		xitertest.TestSeq2(t, xiter.FromSliceIdx([]string{"a", "b", "c"}))
+		xitertest.TestSeq(t, xiter.Generate(func() int { return 1 }), xitertest.WithMaxElements(10))
+		xitertest.TestSeqFunc(t, func() xiter.Seq[int] {
+			ch := make(chan int, 3)
+			ch <- 1
+			ch <- 2
+			ch <- 3
+			close(ch)
+			return xiter.FromChan(ch)
+		})
+		xitertest.TestSeq2Func(t, func() xiter.Seq2[int, string] {
+			return xiter.FromSliceIdx([]string{"a", "b"})
+		})
+		assert.Panics(t, func() { xitertest.WithMaxElements(0) })
+	})
+
+	t.Run("yield after false", func(t *testing.T) {
+		ignoreFalse := func(yield func(int) bool) {
+			for i := 0; i < 20; i++ {
+				yield(i)
+			}
+		}
+		err := xitertest.CheckNoYieldAfterFalse(ignoreFalse)
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "after it returns false at element 1")
+		}
+		// the trailing element is yielded even if the iteration is stopped at the last full chunk.
+		trailing := func(yield func(int) bool) {
+			for i := 0; i < 4; i++ {
+				if !yield(i) {
+					break
+				}
+			}
+			yield(-1)
+		}
+		assert.NotNil(t, xitertest.CheckNoYieldAfterFalse(trailing))
+		assert.Nil(t, xitertest.CheckNoYieldAfterFalse(ints(100)))
+	})
+
+	t.Run("reiterable", func(t *testing.T) {
+		n := 3
+		captured := func(yield func(int) bool) {
+			for ; n > 0; n-- {
+				if !yield(n) {
+					return
+				}
+			}
+		}
+		err := xitertest.CheckReiterable(xiter.Seq[int](captured))
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "after an iteration stopped at element")
+		}
+		assert.Nil(t, xitertest.CheckReiterable(ints(10)))
+	})
+
+	t.Run("deterministic", func(t *testing.T) {
+		i := 0
+		counter := func(yield func(int) bool) {
+			i++
+			yield(i)
+		}
+		err := xitertest.CheckDeterministic(xiter.Seq[int](counter))
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "iteration 2 yields [2]")
+		}
+		r := &recorder{TB: t}
+		xitertest.TestSeq(r, counter, xitertest.WithNondeterministic())
+		assert.Len(t, r.errs, 0)
+	})
+
+	t.Run("panic propagation", func(t *testing.T) {
+		swallow := func(yield func(int) bool) {
+			defer func() { _ = recover() }()
+			ints(3)(yield)
+		}
+		err := xitertest.CheckPanicPropagation(xiter.Seq[int](swallow))
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "does not reach the caller")
+		}
+		wrap := func(yield func(int) bool) {
+			defer func() {
+				if r := recover(); r != nil {
+					panic(fmt.Sprintf("wrapped: %v", r))
+				}
+			}()
+			ints(3)(yield)
+		}
+		err = xitertest.CheckPanicPropagation(xiter.Seq[int](wrap))
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "seq panics with wrapped")
+		}
+		assert.Nil(t, xitertest.CheckPanicPropagation(ints(0)))
+		assert.Nil(t, xitertest.CheckPanicPropagation(ints(3)))
+
+		err = xitertest.CheckNoYieldAfterFalse(func(yield func(int) bool) { panic("boom") })
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "seq panics: boom")
+		}
+	})
+
+	t.Run("goroutine leak", func(t *testing.T) {
+		done := make(chan struct{})
+		defer close(done)
+		leaky := func(yield func(int) bool) {
+			ch := make(chan int)
+			go func() {
+				for i := 0; i < 3; i++ {
+					select {
+					case ch <- i:
+					case <-done:
+						return
+					}
+				}
+				close(ch)
+			}()
+			for v := range ch {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+		err := xitertest.CheckNoGoroutineLeak(xiter.Seq[int](leaky))
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "goroutines are left after an iteration stopped at element 1")
+		}
+		assert.Nil(t, xitertest.CheckNoGoroutineLeak(xiter.FanIn(ints(10), ints(10))))
+	})
+
+	t.Run("report", func(t *testing.T) {
+		r := &recorder{TB: t}
+		xitertest.TestSeq(r, xiter.Seq[int](func(yield func(int) bool) {
+			for i := 0; i < 3; i++ {
+				yield(i)
+			}
+		}))
+		assert.Len(t, r.errs, 1)
+
+		r = &recorder{TB: t}
+		n := 2
+		xitertest.TestSeq2(r, xiter.Seq2[int, int](func(yield func(int, int) bool) {
+			for ; n > 0; n-- {
+				if !yield(n, n) {
+					return
+				}
+			}
+		}))
+		if assert.Len(t, r.errs, 1) {
+			assert.Contains(t, r.errs[0], "iteration 2 yields []")
+		}
+	})
+}
@@ -1,12 +1,15 @@
 package xslice
 
 import (
-	"math/rand"
+	"fmt"
 
 	"github.com/dashjay/xiter/internal/constraints"
 	"github.com/dashjay/xiter/internal/xassert"
+	"github.com/dashjay/xiter/internal/xrand"
 	"github.com/dashjay/xiter/optional"
+	"github.com/dashjay/xiter/union"
 	"github.com/dashjay/xiter/xiter"
+	"github.com/dashjay/xiter/xstat"
 )
 
 // All returns true if all elements in the slice satisfy the condition provided by f.
@@ -526,34 +529,45 @@ func RepeatBy[T any](n int, f func(i int) T) []T {
 }
 
 // Shuffle shuffles the slice.
+// The global source of math/rand is used by default, use xiter.WithRand for a reproducible order.
 //
 // EXAMPLE:
 //
 //	xslice.Shuffle([]int{1, 2, 3}) 👉 [2, 1, 3] (random)
 //	xslice.Shuffle([]int{}) 👉 []int{}
-func Shuffle[T any, Slice ~[]T](in Slice) Slice {
+//	xslice.Shuffle([]int{1, 2, 3}, xiter.WithRand(rand.New(rand.NewSource(1)))) 👉 same order for every run
+func Shuffle[T any, Slice ~[]T](in Slice, opts ...xiter.RandOption) Slice {
 	out := make(Slice, len(in))
 	copy(out, in)
-	rand.Shuffle(len(out), func(i, j int) {
+	newRand(opts).Shuffle(len(out), func(i, j int) {
 		out[i], out[j] = out[j], out[i]
 	})
 	return out
 }
 
 // ShuffleInPlace shuffles the slice.
+// Like Shuffle, xiter.WithRand can be used for a reproducible order.
 //
 // EXAMPLE:
 //
 //	array := []int{1, 2, 3}
 //	xslice.ShuffleInPlace(array) 👉 [2, 1, 3] (random)
-func ShuffleInPlace[T any, Slice ~[]T](in Slice) {
+func ShuffleInPlace[T any, Slice ~[]T](in Slice, opts ...xiter.RandOption) {
 	// why we do not use slices.Shuffle() directly?
 	// because lower version golang may has not package "slices"
-	rand.Shuffle(len(in), func(i, j int) {
+	newRand(opts).Shuffle(len(in), func(i, j int) {
 		in[i], in[j] = in[j], in[i]
 	})
 }
 
+func newRand(opts []xiter.RandOption) *xrand.Options {
+	o := &xrand.Options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
 // Chunk returns a new slice with the elements in the slice chunked into smaller slices of the specified size.
 //
 // EXAMPLE:
@@ -595,6 +609,61 @@ func ChunkInPlace[T any, Slice ~[]T](in Slice, chunkSize int) []Slice {
 	return out
 }
 
+// Window returns the sliding windows of the slice, each window contains size elements
+// and the start of two adjacent windows are step elements apart.
+// Trailing elements which can not fill a whole window are dropped. Every window is a copy.
+//
+// EXAMPLE:
+//
+//	xslice.Window([]int{1, 2, 3, 4, 5}, 3, 1) 👉 [[1, 2, 3], [2, 3, 4], [3, 4, 5]]
+//	xslice.Window([]int{1, 2, 3, 4, 5}, 2, 2) 👉 [[1, 2], [3, 4]]
+//	xslice.Window([]int{1, 2, 3, 4, 5}, 6, 1) 👉 []
+func Window[T any, Slice ~[]T](in Slice, size, step int) []Slice {
+	out := WindowInPlace(in, size, step)
+	for i := range out {
+		out[i] = append(Slice(nil), out[i]...)
+	}
+	return out
+}
+
+// WindowInPlace returns the sliding windows of the slice like Window.
+// This function will not copy the elements, every window shares the memory of the input slice.
+//
+// EXAMPLE:
+//
+//	xslice.WindowInPlace([]int{1, 2, 3, 4, 5}, 3, 1) 👉 [[1, 2, 3], [2, 3, 4], [3, 4, 5]]
+//	xslice.WindowInPlace([]int{1, 2, 3, 4, 5}, 2, 2) 👉 [[1, 2], [3, 4]]
+func WindowInPlace[T any, Slice ~[]T](in Slice, size, step int) []Slice {
+	if size <= 0 || step <= 0 {
+		panic(fmt.Sprintf("window size %d and step %d must be positive", size, step))
+	}
+	if len(in) < size {
+		return []Slice{}
+	}
+	out := make([]Slice, 0, (len(in)-size)/step+1)
+	for i := 0; i+size <= len(in); i += step {
+		out = append(out, in[i:i+size:i+size])
+	}
+	return out
+}
+
+// Pairwise returns the adjacent pairs of the slice.
+//
+// EXAMPLE:
+//
+//	xslice.Pairwise([]int{1, 2, 3}) 👉 [{1, 2}, {2, 3}]
+//	xslice.Pairwise([]int{1}) 👉 []
+func Pairwise[T any](in []T) []union.U2[T, T] {
+	if len(in) < 2 {
+		return []union.U2[T, T]{}
+	}
+	out := make([]union.U2[T, T], 0, len(in)-1)
+	for i := 1; i < len(in); i++ {
+		out = append(out, union.U2[T, T]{T1: in[i-1], T2: in[i]})
+	}
+	return out
+}
+
 // Index returns the index of the first element in the slice that is equal to v.
 // If no such element is found, -1 is returned.
 // EXAMPLE:
@@ -664,6 +733,25 @@ func Uniq[T comparable, Slice ~[]T](in Slice) Slice {
 	return out
 }
 
+// UniqBy returns a new slice with the elements whose keys extracted by f have been seen removed,
+// only the first element of every key is kept.
+//
+// EXAMPLE:
+//
+//	xslice.UniqBy([]string{"apple", "avocado", "banana"}, func(s string) byte { return s[0] }) 👉 [apple banana]
+func UniqBy[T any, K comparable, Slice ~[]T](in Slice, f func(T) K) Slice {
+	seen := make(map[K]struct{}, len(in)/2)
+	out := make(Slice, 0, len(in))
+	for _, v := range in {
+		k := f(v)
+		if _, ok := seen[k]; !ok {
+			seen[k] = struct{}{}
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 // GroupBy returns a map of the slice elements grouped by the given function f.
 //
 // EXAMPLE:
@@ -921,17 +1009,18 @@ func ToMap[T comparable, U any](in []T, f func(T) U) map[T]U {
 //	xslice.Sample([]int{1, 2, 3}, 5) 👉 [2, 1, 3] (random order, all elements)
 //	xslice.Sample([]int{1, 2, 3}, 0) 👉 []int{}
 //	xslice.Sample([]int{}, 3) 👉 []int{}
-func Sample[T any, Slice ~[]T](in Slice, n int) Slice {
+func Sample[T any, Slice ~[]T](in Slice, n int, opts ...xiter.RandOption) Slice {
 	if n <= 0 || len(in) == 0 {
 		return nil
 	}
 	if n >= len(in) {
-		return Shuffle(in)
+		return Shuffle(in, opts...)
 	}
+	r := newRand(opts)
 	out := make(Slice, len(in))
 	copy(out, in)
 	for i := 0; i < n; i++ {
-		j := rand.Intn(len(in)-i) + i //nolint:gosec
+		j := r.Intn(len(in)-i) + i
 		out[i], out[j] = out[j], out[i]
 	}
 	return out[:n]
@@ -945,11 +1034,11 @@ func Sample[T any, Slice ~[]T](in Slice, n int) Slice {
 //	xslice.RandomElement([]int{1, 2, 3, 4, 5}) 👉 3 (random element)
 //	xslice.RandomElement([]int{42}) 👉 42 (always returns the only element)
 //	xslice.RandomElement([]int{}).Ok() 👉 false
-func RandomElement[T any, Slice ~[]T](in Slice) optional.O[T] {
+func RandomElement[T any, Slice ~[]T](in Slice, opts ...xiter.RandOption) optional.O[T] {
 	if len(in) == 0 {
 		return optional.Empty[T]()
 	}
-	return optional.FromValue(in[rand.Intn(len(in))]) //nolint:gosec
+	return optional.FromValue(in[newRand(opts).Intn(len(in))])
 }
 
 // CountBy counts occurrences of each key in the slice, returning a map of keys to counts.
@@ -1093,3 +1182,92 @@ func Mode[T comparable](in []T) optional.O[T] {
 	}
 	return optional.FromValue(mode)
 }
+
+// Variance returns the population variance of the slice, see xstat.Variance.
+//
+// EXAMPLE:
+//
+//	xslice.Variance([]int{2, 4, 4, 4, 5, 5, 7, 9}) 👉 float(4)
+//	xslice.Variance([]int{}) 👉 float(0)
+func Variance[T constraints.Number](in []T) float64 {
+	return xstat.Variance(xiter.FromSlice(in))
+}
+
+// SampleVariance returns the sample variance of the slice, see xstat.SampleVariance.
+//
+// EXAMPLE:
+//
+//	xslice.SampleVariance([]int{1, 2, 3, 4}) 👉 float(1.6666666666666667)
+func SampleVariance[T constraints.Number](in []T) float64 {
+	return xstat.SampleVariance(xiter.FromSlice(in))
+}
+
+// StdDev returns the population standard deviation of the slice, see xstat.StdDev.
+//
+// EXAMPLE:
+//
+//	xslice.StdDev([]int{2, 4, 4, 4, 5, 5, 7, 9}) 👉 float(2)
+func StdDev[T constraints.Number](in []T) float64 {
+	return xstat.StdDev(xiter.FromSlice(in))
+}
+
+// SampleStdDev returns the sample standard deviation of the slice, see xstat.SampleStdDev.
+func SampleStdDev[T constraints.Number](in []T) float64 {
+	return xstat.SampleStdDev(xiter.FromSlice(in))
+}
+
+// Median returns the median of the slice, the slice is not modified.
+//
+// EXAMPLE:
+//
+//	xslice.Median([]int{3, 1, 2}) 👉 float(2)
+//	xslice.Median([]int{4, 1, 3, 2}) 👉 float(2.5)
+func Median[T constraints.Number](in []T) float64 {
+	return xstat.Median(xiter.FromSlice(in))
+}
+
+// Quantile returns the q-quantile of the slice, see xstat.Quantile. The slice is not modified.
+//
+// EXAMPLE:
+//
+//	xslice.Quantile([]int{1, 2, 3, 4, 5}, 0.25) 👉 float(2)
+func Quantile[T constraints.Number](in []T, q float64) float64 {
+	return xstat.Quantile(xiter.FromSlice(in), q)
+}
+
+// Quantiles returns the quantiles of the slice for every q in qs, see xstat.Quantiles.
+//
+// EXAMPLE:
+//
+//	xslice.Quantiles([]int{1, 2, 3, 4, 5}, 0, 0.5, 1) 👉 [1 3 5]
+func Quantiles[T constraints.Number](in []T, qs ...float64) []float64 {
+	return xstat.Quantiles(xiter.FromSlice(in), qs...)
+}
+
+// Histogram counts the items in slice into buckets of the upper bounds, see xstat.BuildHistogram.
+//
+// EXAMPLE:
+//
+//	xslice.Histogram([]int{1, 5, 10, 11}, 5, 10).Counts() 👉 [2 1 1]
+func Histogram[T constraints.Number](in []T, bounds ...float64) *xstat.Histogram {
+	return xstat.BuildHistogram(xiter.FromSlice(in), bounds...)
+}
+
+// Covariance returns the population covariance of x and y, the extra items of the longer slice are ignored.
+//
+// EXAMPLE:
+//
+//	xslice.Covariance([]int{1, 2, 3}, []int{2, 4, 6}) 👉 float(1.3333333333333333)
+func Covariance[T constraints.Number](x, y []T) float64 {
+	return xstat.Covariance(xiter.FromSlice(x), xiter.FromSlice(y))
+}
+
+// Correlation returns the Pearson correlation coefficient of x and y, see xstat.Correlation.
+//
+// EXAMPLE:
+//
+//	xslice.Correlation([]int{1, 2, 3}, []int{2, 4, 6}) 👉 float(1)
+//	xslice.Correlation([]int{1, 2, 3}, []int{3, 2, 1}) 👉 float(-1)
+func Correlation[T constraints.Number](x, y []T) float64 {
+	return xstat.Correlation(xiter.FromSlice(x), xiter.FromSlice(y))
+}
@@ -2,12 +2,14 @@ package xslice_test
 
 import (
 	"fmt"
+	"math/rand"
 	"sort"
 	"strconv"
 	"testing"
 
 	"github.com/dashjay/xiter/internal/constraints"
 	"github.com/dashjay/xiter/optional"
+	"github.com/dashjay/xiter/union"
 	"github.com/dashjay/xiter/xiter"
 	"github.com/dashjay/xiter/xslice"
 	"github.com/stretchr/testify/assert"
@@ -260,6 +262,16 @@ func TestSlices(t *testing.T) {
 		arr := _range(1, 100)
 		xslice.ShuffleInPlace(arr)
 		assert.Len(t, arr, 99)
+
+		seeded := func() xiter.RandOption {
+			return xiter.WithRand(rand.New(rand.NewSource(1)))
+		}
+		shuffled := xslice.Shuffle(_range(0, 100), seeded())
+		assert.Equal(t, shuffled, xslice.Shuffle(_range(0, 100), seeded()))
+		assert.ElementsMatch(t, _range(0, 100), shuffled)
+		inPlace := _range(0, 100)
+		xslice.ShuffleInPlace(inPlace, seeded())
+		assert.Equal(t, shuffled, inPlace)
 	})
 
 	t.Run("chunk and chunk inplace", func(t *testing.T) {
@@ -276,6 +288,36 @@ func TestSlices(t *testing.T) {
 		assert.Len(t, xslice.Chunk([]int{}, 1), 0)
 	})
 
+	t.Run("window and window inplace", func(t *testing.T) {
+		in := []int{1, 2, 3, 4, 5}
+		assert.Equal(t, [][]int{{1, 2, 3}, {2, 3, 4}, {3, 4, 5}}, xslice.Window(in, 3, 1))
+		assert.Equal(t, [][]int{{1, 2, 3}, {2, 3, 4}, {3, 4, 5}}, xslice.WindowInPlace(in, 3, 1))
+		assert.Equal(t, [][]int{{1, 2}, {3, 4}}, xslice.Window(in, 2, 2))
+		assert.Equal(t, [][]int{{1}, {4}}, xslice.WindowInPlace(in, 1, 3))
+		assert.Len(t, xslice.Window(in, 6, 1), 0)
+		assert.Panics(t, func() { xslice.Window(in, 0, 1) })
+
+		for _, w := range xslice.Window(in, 2, 1) {
+			w[0] = 0
+		}
+		assert.Equal(t, []int{1, 2, 3, 4, 5}, in)
+		inPlace := xslice.WindowInPlace(in, 2, 1)
+		assert.Equal(t, &in[1], &inPlace[1][0])
+
+		// windows must be the same as the lazy version
+		for size := 1; size < 5; size++ {
+			for step := 1; step < 5; step++ {
+				assert.Equal(t, xiter.ToSlice(xiter.Window(xiter.FromSlice(_range(0, 10)), size, step)), xslice.Window(_range(0, 10), size, step))
+			}
+		}
+	})
+
+	t.Run("pairwise", func(t *testing.T) {
+		assert.Equal(t, []union.U2[int, int]{{T1: 1, T2: 2}, {T1: 2, T2: 3}}, xslice.Pairwise([]int{1, 2, 3}))
+		assert.Len(t, xslice.Pairwise([]int{1}), 0)
+		assert.Len(t, xslice.Pairwise([]int{}), 0)
+	})
+
 	t.Run("index", func(t *testing.T) {
 		assert.Equal(t, 50, xslice.Index(_range(0, 101), 50))
 		assert.Equal(t, -1, xslice.Index(_range(0, 101), 6666))
@@ -292,6 +334,8 @@ func TestSlices(t *testing.T) {
 	})
 	t.Run("uniq", func(t *testing.T) {
 		assert.Equal(t, []int{1, 2, 3, 4}, xslice.Uniq([]int{1, 2, 3, 2, 4}))
+		assert.Equal(t, []string{"apple", "banana"}, xslice.UniqBy([]string{"apple", "avocado", "banana"}, func(s string) byte { return s[0] }))
+		assert.Len(t, xslice.UniqBy([]int{}, func(v int) int { return v }), 0)
 	})
 	t.Run("group by", func(t *testing.T) {
 		groupedBy := xslice.GroupBy([]int{0, 1, 2, 3, 4}, func(i int) string {
@@ -486,6 +530,13 @@ func TestSlices(t *testing.T) {
 		elem4 := xslice.RandomElement(strings)
 		assert.True(t, elem4.Ok())
 		assert.Contains(t, strings, elem4.Must())
+		// Test with seeded rand
+		seeded := func() xiter.RandOption {
+			return xiter.WithRand(rand.New(rand.NewSource(1)))
+		}
+		assert.Equal(t, xslice.RandomElement(_range(0, 100), seeded()), xslice.RandomElement(_range(0, 100), seeded()))
+		assert.Equal(t, xslice.Sample(_range(0, 100), 10, seeded()), xslice.Sample(_range(0, 100), 10, seeded()))
+		assert.Equal(t, xslice.Sample(_range(0, 5), 10, seeded()), xslice.Shuffle(_range(0, 5), seeded()))
 	})
 
 	t.Run("count by", func(t *testing.T) {
@@ -641,4 +692,26 @@ func TestSlices(t *testing.T) {
 		assert.Equal(t, 1, mode6.Must())
 	})
 
+	t.Run("statistics", func(t *testing.T) {
+		in := []int{2, 4, 4, 4, 5, 5, 7, 9}
+		assert.Equal(t, float64(4), xslice.Variance(in))
+		assert.Equal(t, float64(2), xslice.StdDev(in))
+		assert.InDelta(t, 32.0/7, xslice.SampleVariance(in), 1e-9)
+		assert.InDelta(t, 2.138089935, xslice.SampleStdDev(in), 1e-9)
+		assert.Equal(t, float64(0), xslice.Variance([]int{}))
+
+		assert.Equal(t, float64(4.5), xslice.Median(in))
+		unsorted := []int{3, 1, 2}
+		assert.Equal(t, float64(2), xslice.Median(unsorted))
+		assert.Equal(t, []int{3, 1, 2}, unsorted)
+		assert.Equal(t, float64(2), xslice.Quantile([]int{5, 4, 3, 2, 1}, 0.25))
+		assert.Equal(t, []float64{2, 4.5, 9}, xslice.Quantiles(in, 0, 0.5, 1))
+
+		h := xslice.Histogram(in, 3, 5)
+		assert.Equal(t, []int{1, 5, 2}, h.Counts())
+
+		assert.InDelta(t, 4.0/3, xslice.Covariance([]int{1, 2, 3}, []int{2, 4, 6}), 1e-9)
+		assert.InDelta(t, 1, xslice.Correlation([]int{1, 2, 3}, []int{2, 4, 6, 100}), 1e-9)
+		assert.InDelta(t, -1, xslice.Correlation([]int{1, 2, 3}, []int{3, 2, 1}), 1e-9)
+	})
 }
@@ -0,0 +1,114 @@
+package xstat
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/dashjay/xiter/internal/constraints"
+	"github.com/dashjay/xiter/xiter"
+)
+
+// Histogram counts values into buckets defined by their upper bounds.
+// The bucket i counts values in (bounds[i-1], bounds[i]],
+// and an extra overflow bucket counts values greater than the last bound (and NaN).
+type Histogram struct {
+	bounds []float64
+	counts []int
+	total  int
+}
+
+// NewHistogram returns an empty Histogram with buckets of the upper bounds,
+// it panics if bounds are not strictly increasing.
+//
+// EXAMPLE:
+//
+//	h := xstat.NewHistogram(0, 10, 100)
+//	h.Add(5)
+//	h.Add(1000)
+//	h.Counts() 👉 [0 1 0 1]
+func NewHistogram(bounds ...float64) *Histogram {
+	for i := 1; i < len(bounds); i++ {
+		if !(bounds[i-1] < bounds[i]) {
+			panic(fmt.Sprintf("histogram bounds must be strictly increasing, got %v", bounds))
+		}
+	}
+	b := make([]float64, len(bounds))
+	copy(b, bounds)
+	return &Histogram{
+		bounds: b,
+		counts: make([]int, len(bounds)+1),
+	}
+}
+
+// Add counts x into its bucket.
+func (h *Histogram) Add(x float64) {
+	h.counts[sort.SearchFloat64s(h.bounds, x)]++
+	h.total++
+}
+
+// Bounds returns the upper bounds of buckets, the overflow bucket is not included.
+func (h *Histogram) Bounds() []float64 {
+	return h.bounds
+}
+
+// Counts returns the count of every bucket, the last one is the overflow bucket.
+func (h *Histogram) Counts() []int {
+	return h.counts
+}
+
+// Total returns the number of values added.
+func (h *Histogram) Total() int {
+	return h.total
+}
+
+// BuildHistogram counts the values of seq into a Histogram with buckets of the upper bounds.
+//
+// EXAMPLE:
+//
+//	h := xstat.BuildHistogram(xiter.FromSlice([]int{1, 5, 10, 11}), xstat.LinearBuckets(5, 5, 2)...)
+//	h.Bounds() 👉 [5 10]
+//	h.Counts() 👉 [2 1 1]
+func BuildHistogram[T constraints.Number](seq xiter.Seq[T], bounds ...float64) *Histogram {
+	h := NewHistogram(bounds...)
+	seq(func(v T) bool {
+		h.Add(float64(v))
+		return true
+	})
+	return h
+}
+
+// LinearBuckets returns count upper bounds, the first is start and each next one is width larger.
+// It panics if count is not positive or width is not positive.
+//
+// EXAMPLE:
+//
+//	xstat.LinearBuckets(0, 10, 4) 👉 [0 10 20 30]
+func LinearBuckets(start, width float64, count int) []float64 {
+	if count <= 0 || !(width > 0) {
+		panic(fmt.Sprintf("invalid linear buckets: width %v, count %d", width, count))
+	}
+	bounds := make([]float64, count)
+	for i := range bounds {
+		bounds[i] = start + width*float64(i)
+	}
+	return bounds
+}
+
+// ExponentialBuckets returns count upper bounds, the first is start and each next one is factor times larger.
+// It panics if count is not positive, start is not positive or factor is not greater than 1.
+//
+// EXAMPLE:
+//
+//	xstat.ExponentialBuckets(1, 2, 4) 👉 [1 2 4 8]
+func ExponentialBuckets(start, factor float64, count int) []float64 {
+	if count <= 0 || !(start > 0) || !(factor > 1) || math.IsInf(start, 0) {
+		panic(fmt.Sprintf("invalid exponential buckets: start %v, factor %v, count %d", start, factor, count))
+	}
+	bounds := make([]float64, count)
+	for i := range bounds {
+		bounds[i] = start
+		start *= factor
+	}
+	return bounds
+}
@@ -0,0 +1,208 @@
+package xstat
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/dashjay/xiter/internal/constraints"
+	"github.com/dashjay/xiter/xiter"
+)
+
+// Median returns the exact median of seq, the mean of the two middle values if seq has an even number of values.
+// Like Quantile, all values of seq are held in memory, use ApproxQuantile for large sequences.
+//
+// EXAMPLE:
+//
+//	xstat.Median(xiter.FromSlice([]int{3, 1, 2})) 👉 2
+//	xstat.Median(xiter.FromSlice([]int{4, 1, 3, 2})) 👉 2.5
+func Median[T constraints.Number](seq xiter.Seq[T]) float64 {
+	return Quantile(seq, 0.5)
+}
+
+// Quantile returns the exact q-quantile of seq, q must be in [0, 1].
+// The result is interpolated linearly between the closest ranks, so the 0.5-quantile is the median.
+// All values of seq are held in memory, use ApproxQuantile for large sequences.
+//
+// EXAMPLE:
+//
+//	xstat.Quantile(xiter.FromSlice([]int{1, 2, 3, 4, 5}), 0.25) 👉 2
+func Quantile[T constraints.Number](seq xiter.Seq[T], q float64) float64 {
+	return Quantiles(seq, q)[0]
+}
+
+// Quantiles returns the exact quantiles of seq for every q in qs, seq is iterated only once.
+func Quantiles[T constraints.Number](seq xiter.Seq[T], qs ...float64) []float64 {
+	for _, q := range qs {
+		mustBeQuantile(q)
+	}
+	var s []float64
+	seq(func(v T) bool {
+		s = append(s, float64(v))
+		return true
+	})
+	sort.Float64s(s)
+	out := make([]float64, 0, len(qs))
+	for _, q := range qs {
+		out = append(out, sortedQuantile(s, q))
+	}
+	return out
+}
+
+// sortedQuantile returns the q-quantile of the sorted s by linear interpolation.
+func sortedQuantile(s []float64, q float64) float64 {
+	if len(s) == 0 {
+		return 0
+	}
+	h := q * float64(len(s)-1)
+	lo := int(h)
+	if lo >= len(s)-1 {
+		return s[len(s)-1]
+	}
+	return s[lo] + (h-float64(lo))*(s[lo+1]-s[lo])
+}
+
+func mustBeQuantile(q float64) {
+	if !(q >= 0 && q <= 1) {
+		panic(fmt.Sprintf("quantile %v out of range [0, 1]", q))
+	}
+}
+
+// P2Quantile estimates a quantile of a stream with the P² algorithm of Jain and Chlamtac,
+// it uses O(1) memory no matter how many values are added.
+// The estimation is exact for the first 5 values.
+//
+// EXAMPLE:
+//
+//	p99 := xstat.NewP2Quantile(0.99)
+//	for _, latency := range latencies {
+//		p99.Add(latency)
+//	}
+//	p99.Value() // the estimated 99th percentile
+type P2Quantile struct {
+	p     float64
+	count int
+	q     [5]float64 // heights of markers
+	n     [5]float64 // actual positions of markers
+	np    [5]float64 // desired positions of markers
+	dn    [5]float64 // increments of desired positions
+}
+
+// NewP2Quantile returns a P2Quantile estimating the p-quantile, it panics if p is not in [0, 1].
+func NewP2Quantile(p float64) *P2Quantile {
+	mustBeQuantile(p)
+	return &P2Quantile{
+		p:  p,
+		n:  [5]float64{1, 2, 3, 4, 5},
+		np: [5]float64{1, 1 + 2*p, 1 + 4*p, 3 + 2*p, 5},
+		dn: [5]float64{0, p / 2, p, (1 + p) / 2, 1},
+	}
+}
+
+// Add adds x to the estimation.
+func (e *P2Quantile) Add(x float64) {
+	if e.count < 5 {
+		e.q[e.count] = x
+		e.count++
+		if e.count == 5 {
+			sort.Float64s(e.q[:])
+		}
+		return
+	}
+	e.count++
+
+	// find the cell k which x falls in, and update the extreme markers.
+	var k int
+	switch {
+	case x < e.q[0]:
+		e.q[0] = x
+		k = 0
+	case x >= e.q[4]:
+		e.q[4] = x
+		k = 3
+	default:
+		for k = 0; k < 3; k++ {
+			if x < e.q[k+1] {
+				break
+			}
+		}
+	}
+	for i := k + 1; i < 5; i++ {
+		e.n[i]++
+	}
+	for i := range e.np {
+		e.np[i] += e.dn[i]
+	}
+
+	// adjust the heights of the middle markers if they are off their desired positions.
+	for i := 1; i < 4; i++ {
+		d := e.np[i] - e.n[i]
+		if (d >= 1 && e.n[i+1]-e.n[i] > 1) || (d <= -1 && e.n[i-1]-e.n[i] < -1) {
+			s := 1.0
+			if d < 0 {
+				s = -1
+			}
+			q := e.parabolic(i, s)
+			if e.q[i-1] < q && q < e.q[i+1] {
+				e.q[i] = q
+			} else {
+				e.q[i] = e.linear(i, s)
+			}
+			e.n[i] += s
+		}
+	}
+}
+
+func (e *P2Quantile) parabolic(i int, s float64) float64 {
+	return e.q[i] + s/(e.n[i+1]-e.n[i-1])*
+		((e.n[i]-e.n[i-1]+s)*(e.q[i+1]-e.q[i])/(e.n[i+1]-e.n[i])+
+			(e.n[i+1]-e.n[i]-s)*(e.q[i]-e.q[i-1])/(e.n[i]-e.n[i-1]))
+}
+
+func (e *P2Quantile) linear(i int, s float64) float64 {
+	j := i + int(s)
+	return e.q[i] + s*(e.q[j]-e.q[i])/(e.n[j]-e.n[i])
+}
+
+// Count returns the number of values added.
+func (e *P2Quantile) Count() int {
+	return e.count
+}
+
+// Value returns the estimated quantile, 0 if no value is added.
+func (e *P2Quantile) Value() float64 {
+	if e.count > 5 {
+		return e.q[2]
+	}
+	s := make([]float64, e.count)
+	copy(s, e.q[:e.count])
+	sort.Float64s(s)
+	return sortedQuantile(s, e.p)
+}
+
+// ApproxQuantile returns the estimated q-quantile of seq with P2Quantile, in one pass and O(1) memory.
+//
+// EXAMPLE:
+//
+//	xstat.ApproxQuantile(xiter.Range(0, 100001, 1), 0.5) 👉 50000 (approximately)
+func ApproxQuantile[T constraints.Number](seq xiter.Seq[T], q float64) float64 {
+	return ApproxQuantiles(seq, q)[0]
+}
+
+// ApproxQuantiles returns the estimated quantiles of seq for every q in qs, seq is iterated only once.
+func ApproxQuantiles[T constraints.Number](seq xiter.Seq[T], qs ...float64) []float64 {
+	estimators := make([]*P2Quantile, 0, len(qs))
+	for _, q := range qs {
+		estimators = append(estimators, NewP2Quantile(q))
+	}
+	seq(func(v T) bool {
+		for _, e := range estimators {
+			e.Add(float64(v))
+		}
+		return true
+	})
+	out := make([]float64, 0, len(qs))
+	for _, e := range estimators {
+		out = append(out, e.Value())
+	}
+	return out
+}
@@ -0,0 +1,195 @@
+// Package xstat provides one-pass statistics over xiter.Seq,
+// such as variance, standard deviation, quantiles, histograms, covariance and correlation.
+//
+// All functions return float64, so the results of integer sequences are not truncated,
+// and they return 0 for empty sequences like xiter.AvgFromSeq.
+// The slice versions of these functions are provided in package xslice.
+package xstat
+
+import (
+	"math"
+
+	"github.com/dashjay/xiter/internal/constraints"
+	"github.com/dashjay/xiter/xiter"
+)
+
+// Welford accumulates the count, mean and variance of values in one pass
+// with the numerically stable algorithm of Welford.
+// The zero value is ready to use.
+//
+// EXAMPLE:
+//
+//	var w xstat.Welford
+//	for _, v := range []float64{2, 4, 4, 4, 5, 5, 7, 9} {
+//		w.Add(v)
+//	}
+//	w.Mean() 👉 5
+//	w.StdDev() 👉 2
+type Welford struct {
+	n    int
+	mean float64
+	m2   float64
+}
+
+// Add adds x to the accumulator.
+func (w *Welford) Add(x float64) {
+	w.n++
+	delta := x - w.mean
+	w.mean += delta / float64(w.n)
+	w.m2 += delta * (x - w.mean)
+}
+
+// Count returns the number of values added.
+func (w *Welford) Count() int {
+	return w.n
+}
+
+// Mean returns the mean of values added, 0 if no value is added.
+func (w *Welford) Mean() float64 {
+	return w.mean
+}
+
+// Variance returns the population variance of values added, 0 if no value is added.
+func (w *Welford) Variance() float64 {
+	if w.n == 0 {
+		return 0
+	}
+	return w.m2 / float64(w.n)
+}
+
+// SampleVariance returns the sample variance (with Bessel's correction) of values added,
+// 0 if less than 2 values are added.
+func (w *Welford) SampleVariance() float64 {
+	if w.n < 2 {
+		return 0
+	}
+	return w.m2 / float64(w.n-1)
+}
+
+// StdDev returns the population standard deviation of values added.
+func (w *Welford) StdDev() float64 {
+	return math.Sqrt(w.Variance())
+}
+
+// SampleStdDev returns the sample standard deviation of values added.
+func (w *Welford) SampleStdDev() float64 {
+	return math.Sqrt(w.SampleVariance())
+}
+
+func welford[T constraints.Number](seq xiter.Seq[T]) *Welford {
+	w := &Welford{}
+	seq(func(v T) bool {
+		w.Add(float64(v))
+		return true
+	})
+	return w
+}
+
+// Mean returns the mean of seq as float64.
+// Unlike xiter.Mean, the mean of integers is not truncated.
+//
+// EXAMPLE:
+//
+//	xstat.Mean(xiter.FromSlice([]int{1, 2})) 👉 1.5
+func Mean[T constraints.Number](seq xiter.Seq[T]) float64 {
+	return welford(seq).Mean()
+}
+
+// Variance returns the population variance of seq.
+//
+// EXAMPLE:
+//
+//	xstat.Variance(xiter.FromSlice([]int{2, 4, 4, 4, 5, 5, 7, 9})) 👉 4
+func Variance[T constraints.Number](seq xiter.Seq[T]) float64 {
+	return welford(seq).Variance()
+}
+
+// SampleVariance returns the sample variance (with Bessel's correction) of seq, 0 if seq has less than 2 values.
+//
+// EXAMPLE:
+//
+//	xstat.SampleVariance(xiter.FromSlice([]int{1, 2, 3, 4})) 👉 1.6666666666666667
+func SampleVariance[T constraints.Number](seq xiter.Seq[T]) float64 {
+	return welford(seq).SampleVariance()
+}
+
+// StdDev returns the population standard deviation of seq.
+//
+// EXAMPLE:
+//
+//	xstat.StdDev(xiter.FromSlice([]int{2, 4, 4, 4, 5, 5, 7, 9})) 👉 2
+func StdDev[T constraints.Number](seq xiter.Seq[T]) float64 {
+	return welford(seq).StdDev()
+}
+
+// SampleStdDev returns the sample standard deviation of seq, 0 if seq has less than 2 values.
+func SampleStdDev[T constraints.Number](seq xiter.Seq[T]) float64 {
+	return welford(seq).SampleStdDev()
+}
+
+// coMoments accumulates the co-moment of pairs in one pass like Welford.
+type coMoments struct {
+	x, y Welford
+	c    float64
+}
+
+func (m *coMoments) add(x, y float64) {
+	dx := x - m.x.mean
+	m.x.Add(x)
+	m.y.Add(y)
+	m.c += dx * (y - m.y.mean)
+}
+
+func zipCoMoments[T constraints.Number](x, y xiter.Seq[T]) *coMoments {
+	m := &coMoments{}
+	xiter.Zip(x, y)(func(z xiter.Zipped[T, T]) bool {
+		if !z.Ok1 || !z.Ok2 {
+			return false
+		}
+		m.add(float64(z.V1), float64(z.V2))
+		return true
+	})
+	return m
+}
+
+// Covariance returns the population covariance of x and y, which are zipped like xiter.Zip.
+// The iteration stops when either x or y ends, the remaining values of the longer one are ignored.
+//
+// EXAMPLE:
+//
+//	x := xiter.FromSlice([]int{1, 2, 3})
+//	y := xiter.FromSlice([]int{2, 4, 6})
+//	xstat.Covariance(x, y) 👉 1.3333333333333333
+func Covariance[T constraints.Number](x, y xiter.Seq[T]) float64 {
+	m := zipCoMoments(x, y)
+	if m.x.n == 0 {
+		return 0
+	}
+	return m.c / float64(m.x.n)
+}
+
+// SampleCovariance returns the sample covariance of x and y, 0 if there are less than 2 pairs.
+// Like Covariance, x and y are zipped.
+func SampleCovariance[T constraints.Number](x, y xiter.Seq[T]) float64 {
+	m := zipCoMoments(x, y)
+	if m.x.n < 2 {
+		return 0
+	}
+	return m.c / float64(m.x.n-1)
+}
+
+// Correlation returns the Pearson correlation coefficient of x and y in [-1, 1].
+// Like Covariance, x and y are zipped. It returns 0 if x or y is constant or empty.
+//
+// EXAMPLE:
+//
+//	x := xiter.FromSlice([]int{1, 2, 3})
+//	xstat.Correlation(x, xiter.FromSlice([]int{2, 4, 6})) 👉 1
+//	xstat.Correlation(x, xiter.FromSlice([]int{3, 2, 1})) 👉 -1
+func Correlation[T constraints.Number](x, y xiter.Seq[T]) float64 {
+	m := zipCoMoments(x, y)
+	if m.x.m2 == 0 || m.y.m2 == 0 {
+		return 0
+	}
+	return m.c / math.Sqrt(m.x.m2*m.y.m2)
+}
@@ -0,0 +1,128 @@
+package xstat_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dashjay/xiter/xiter"
+	"github.com/dashjay/xiter/xstat"
+)
+
+func TestXStat(t *testing.T) {
+	in := xiter.FromSlice([]int{2, 4, 4, 4, 5, 5, 7, 9})
+	empty := xiter.FromSlice([]int{})
+
+	t.Run("welford", func(t *testing.T) {
+		var w xstat.Welford
+		assert.Equal(t, float64(0), w.Variance())
+		for _, v := range []float64{2, 4, 4, 4, 5, 5, 7, 9} {
+			w.Add(v)
+		}
+		assert.Equal(t, 8, w.Count())
+		assert.Equal(t, float64(5), w.Mean())
+		assert.Equal(t, float64(4), w.Variance())
+		assert.Equal(t, float64(2), w.StdDev())
+		assert.InDelta(t, 32.0/7, w.SampleVariance(), 1e-9)
+		assert.InDelta(t, math.Sqrt(32.0/7), w.SampleStdDev(), 1e-9)
+
+		// numerically stable with a large offset
+		var large xstat.Welford
+		for _, v := range []float64{4, 7, 13, 16} {
+			large.Add(1e9 + v)
+		}
+		assert.InDelta(t, 30, large.SampleVariance(), 1e-6)
+	})
+
+	t.Run("mean and variance", func(t *testing.T) {
+		assert.Equal(t, 1.5, xstat.Mean(xiter.FromSlice([]int{1, 2})))
+		assert.Equal(t, 1, xiter.Mean(xiter.FromSlice([]int{1, 2})))
+		assert.Equal(t, float64(4), xstat.Variance(in))
+		assert.Equal(t, float64(2), xstat.StdDev(in))
+		assert.InDelta(t, 5.0/3, xstat.SampleVariance(xiter.FromSlice([]int{1, 2, 3, 4})), 1e-9)
+		assert.InDelta(t, math.Sqrt(5.0/3), xstat.SampleStdDev(xiter.FromSlice([]int{1, 2, 3, 4})), 1e-9)
+
+		assert.Equal(t, float64(0), xstat.Mean(empty))
+		assert.Equal(t, float64(0), xstat.Variance(empty))
+		assert.Equal(t, float64(0), xstat.SampleVariance(xiter.FromSlice([]int{1})))
+	})
+
+	t.Run("quantile", func(t *testing.T) {
+		assert.Equal(t, float64(4.5), xstat.Median(in))
+		assert.Equal(t, float64(2), xstat.Median(xiter.FromSlice([]int{3, 1, 2})))
+		assert.Equal(t, 2.5, xstat.Median(xiter.FromSlice([]int{4, 1, 3, 2})))
+		assert.Equal(t, float64(0), xstat.Median(empty))
+		assert.Equal(t, float64(2), xstat.Quantile(xiter.FromSlice([]int{1, 2, 3, 4, 5}), 0.25))
+		assert.Equal(t, 1.4, xstat.Quantile(xiter.FromSlice([]float64{1, 2}), 0.4))
+		assert.Equal(t, []float64{2, 4, 9}, xstat.Quantiles(in, 0, 0.25, 1))
+		assert.Panics(t, func() { xstat.Quantile(in, 1.5) })
+		assert.Panics(t, func() { xstat.Quantile(in, math.NaN()) })
+	})
+
+	t.Run("approx quantile", func(t *testing.T) {
+		// exact for at most 5 values
+		assert.Equal(t, float64(2), xstat.ApproxQuantile(xiter.FromSlice([]int{3, 1, 2}), 0.5))
+		assert.InDelta(t, 4.96, xstat.ApproxQuantile(xiter.FromSlice([]int{5, 3, 1, 4, 2}), 0.99), 1e-9)
+		assert.Equal(t, float64(0), xstat.ApproxQuantile(empty, 0.5))
+
+		r := rand.New(rand.NewSource(1))
+		values := make([]float64, 0, 100000)
+		for i := 0; i < 100000; i++ {
+			values = append(values, r.Float64()*1000)
+		}
+		qs := []float64{0.1, 0.5, 0.9, 0.99}
+		exact := xstat.Quantiles(xiter.FromSlice(values), qs...)
+		approx := xstat.ApproxQuantiles(xiter.FromSlice(values), qs...)
+		for i := range qs {
+			assert.InDelta(t, exact[i], approx[i], 5, "q=%v", qs[i])
+		}
+
+		e := xstat.NewP2Quantile(0.5)
+		xiter.ForEach(xiter.Range(0, 10001, 1), func(v int) bool {
+			e.Add(float64(v))
+			return true
+		})
+		assert.Equal(t, 10001, e.Count())
+		assert.InDelta(t, 5000, e.Value(), 50)
+		assert.Panics(t, func() { xstat.NewP2Quantile(-0.1) })
+	})
+
+	t.Run("histogram", func(t *testing.T) {
+		h := xstat.BuildHistogram(xiter.FromSlice([]int{1, 5, 10, 11}), xstat.LinearBuckets(5, 5, 2)...)
+		assert.Equal(t, []float64{5, 10}, h.Bounds())
+		assert.Equal(t, []int{2, 1, 1}, h.Counts())
+		assert.Equal(t, 4, h.Total())
+
+		h = xstat.NewHistogram(0, 10, 100)
+		h.Add(-1)
+		h.Add(5)
+		h.Add(1000)
+		h.Add(math.NaN())
+		assert.Equal(t, []int{1, 1, 0, 2}, h.Counts())
+
+		assert.Equal(t, []int{0}, xstat.BuildHistogram(empty).Counts())
+		assert.Equal(t, []float64{0, 10, 20, 30}, xstat.LinearBuckets(0, 10, 4))
+		assert.Equal(t, []float64{1, 2, 4, 8}, xstat.ExponentialBuckets(1, 2, 4))
+		assert.Panics(t, func() { xstat.NewHistogram(1, 1) })
+		assert.Panics(t, func() { xstat.LinearBuckets(0, 0, 4) })
+		assert.Panics(t, func() { xstat.ExponentialBuckets(1, 1, 4) })
+	})
+
+	t.Run("covariance and correlation", func(t *testing.T) {
+		x := xiter.FromSlice([]int{1, 2, 3})
+		assert.InDelta(t, 4.0/3, xstat.Covariance(x, xiter.FromSlice([]int{2, 4, 6})), 1e-9)
+		assert.InDelta(t, 2, xstat.SampleCovariance(x, xiter.FromSlice([]int{2, 4, 6})), 1e-9)
+		assert.InDelta(t, 1, xstat.Correlation(x, xiter.FromSlice([]int{2, 4, 6})), 1e-9)
+		assert.InDelta(t, -1, xstat.Correlation(x, xiter.FromSlice([]int{3, 2, 1})), 1e-9)
+
+		// zipped, the extra values are ignored
+		assert.InDelta(t, 1, xstat.Correlation(x, xiter.FromSlice([]int{2, 4, 6, -100})), 1e-9)
+		assert.InDelta(t, 1, xstat.Correlation(xiter.Range(1, 1<<62, 1), xiter.FromSlice([]int{2, 4, 6})), 1e-9)
+
+		assert.Equal(t, float64(0), xstat.Correlation(x, xiter.FromSlice([]int{1, 1, 1})))
+		assert.Equal(t, float64(0), xstat.Covariance(empty, empty))
+		assert.Equal(t, float64(0), xstat.SampleCovariance(x, xiter.FromSlice([]int{1})))
+	})
+}